@@ -0,0 +1,328 @@
+package telnet
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"time"
+)
+
+// buffer is a goroutine-safe storage for data received from the remote
+// server. It remembers when the data was received last time.
+//
+// The received data is split into lines which are passed to onLine with
+// the captured flag. The own flag is set for the command echo and
+// the sentinel lines. While capturing, the data is also stored as a command
+// response. While streaming, the response lines are passed to stream
+// instead of being stored. The first captured line satisfying echo marks
+// the beginning of the command output.
+//
+// If limit is positive, neither the response nor the unfinished line grows
+// beyond limit bytes. The response overflow is handled according to policy,
+// the unfinished line is always truncated.
+type buffer struct {
+	mu         sync.Mutex
+	data       bytes.Buffer
+	line       bytes.Buffer
+	received   time.Time
+	capturing  bool
+	onLine     func(line string, captured bool, own bool)
+	limit      int
+	policy     OverflowPolicy
+	overflowed bool
+	dropped    uint64
+	echo       func(line string) bool
+	echoed     bool
+
+	// Streaming state. The deliver mutex is held while stream is called
+	// outside mu.
+	stream     func(line string)
+	sentinel   bool
+	delivering bool
+	deliver    sync.Mutex
+}
+
+// Write appends p to the buffer.
+func (b *buffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+
+	b.received = time.Now()
+
+	n := len(p)
+
+	if b.capturing && b.stream == nil {
+		b.capture(p)
+	}
+
+	var streamed []string
+
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i == -1 {
+			b.appendLine(p)
+
+			break
+		}
+
+		b.appendLine(p[:i])
+		p = p[i+1:]
+
+		line := strings.TrimRight(strings.ReplaceAll(b.line.String(), NullString, ""), "\r")
+		b.line.Reset()
+
+		if line == "" {
+			continue
+		}
+
+		// The command echo and the sentinel lines are caused by the client.
+		own := b.capturing && strings.Contains(line, SentinelCommand)
+
+		if b.capturing && !b.sentinel {
+			if own {
+				// The sentinel echo and answer end the response.
+				b.sentinel = true
+			} else {
+				if !b.echoed && b.echo != nil && b.echo(line) {
+					b.echoed = true
+					own = true
+				}
+
+				if b.stream != nil {
+					streamed = append(streamed, line)
+				}
+			}
+		}
+
+		if b.onLine != nil {
+			b.onLine(line, b.capturing, own)
+		}
+	}
+
+	if len(streamed) == 0 {
+		b.mu.Unlock()
+
+		return n, nil
+	}
+
+	// Do not hold mu while stream is called, the response is not
+	// considered complete until it returns.
+	stream := b.stream
+	b.delivering = true
+	b.deliver.Lock()
+	defer b.deliver.Unlock()
+	b.mu.Unlock()
+
+	for _, line := range streamed {
+		stream(line)
+	}
+
+	b.mu.Lock()
+	b.delivering = false
+	b.received = time.Now()
+	b.mu.Unlock()
+
+	return n, nil
+}
+
+// appendLine appends p to the unfinished line. While capturing, the bytes
+// which don't fit are counted as dropped by capture.
+func (b *buffer) appendLine(p []byte) {
+	if b.capturing && b.stream == nil {
+		b.line.Write(b.fit(b.line.Len(), p))
+
+		return
+	}
+
+	b.line.Write(b.truncate(b.line.Len(), p))
+}
+
+// capture appends p to the response according to the overflow policy.
+func (b *buffer) capture(p []byte) {
+	if b.limit <= 0 || b.data.Len()+len(p) <= b.limit {
+		b.data.Write(p)
+
+		return
+	}
+
+	b.overflowed = true
+
+	if b.policy != DropOldest {
+		b.data.Write(b.truncate(b.data.Len(), p))
+
+		return
+	}
+
+	if len(p) >= b.limit {
+		b.dropped += uint64(b.data.Len() + len(p) - b.limit)
+		b.data.Reset()
+		b.data.Write(p[len(p)-b.limit:])
+
+		return
+	}
+
+	excess := b.data.Len() + len(p) - b.limit
+	b.dropped += uint64(excess)
+	b.data.Next(excess)
+	b.data.Write(p)
+}
+
+// truncate returns the part of p which fits into limit bytes together with
+// size bytes already stored. The rest of p is counted as dropped.
+func (b *buffer) truncate(size int, p []byte) []byte {
+	kept := b.fit(size, p)
+	b.dropped += uint64(len(p) - len(kept))
+
+	return kept
+}
+
+// fit returns the part of p which fits into limit bytes together with
+// size bytes already stored.
+func (b *buffer) fit(size int, p []byte) []byte {
+	if b.limit <= 0 || size+len(p) <= b.limit {
+		return p
+	}
+
+	return p[:max(b.limit-size, 0)]
+}
+
+// Capture starts storing the received data as a command response.
+func (b *buffer) Capture() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.capturing = true
+	b.overflowed = false
+	b.sentinel = false
+	b.echoed = false
+	b.data.Reset()
+}
+
+// Stream starts passing the received response lines to stream. The lines
+// are not stored. Stream is called from the writer goroutine.
+func (b *buffer) Stream(stream func(line string)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.capturing = true
+	b.stream = stream
+	b.overflowed = false
+	b.sentinel = false
+	b.echoed = false
+	b.data.Reset()
+}
+
+// Discard stops passing the received response lines to stream, the rest
+// of the response is stored as by Capture. It waits for the stream call
+// in progress to return.
+func (b *buffer) Discard() {
+	b.mu.Lock()
+	b.stream = nil
+	b.mu.Unlock()
+
+	b.deliver.Lock()
+	defer b.deliver.Unlock()
+}
+
+// Echo sets the function recognising the command echo line, see Echoed.
+func (b *buffer) Echo(echo func(line string) bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.echo = echo
+	b.echoed = false
+}
+
+// Release stops storing the received data as a command response. It waits
+// for the stream call in progress to return.
+func (b *buffer) Release() {
+	b.mu.Lock()
+	b.capturing = false
+	b.stream = nil
+	b.overflowed = false
+	b.sentinel = false
+	b.echo = nil
+	b.echoed = false
+	b.data.Reset()
+	b.mu.Unlock()
+
+	b.deliver.Lock()
+	defer b.deliver.Unlock()
+}
+
+// Received returns the time when the data was received last time. While
+// the received lines are streamed, it returns the current time.
+func (b *buffer) Received() time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.delivering {
+		return time.Now()
+	}
+
+	return b.received
+}
+
+// Streaming reports whether the received response lines are passed
+// to stream.
+func (b *buffer) Streaming() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.stream != nil
+}
+
+// Sentinel reports whether the answer to SentinelCommand is received
+// since the last Capture or Stream.
+func (b *buffer) Sentinel() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.sentinel
+}
+
+// Echoed reports whether the command echo line is received since the last
+// Capture or Stream. It is always true if the echo function is not set.
+func (b *buffer) Echoed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.echo == nil || b.echoed
+}
+
+// Match reports whether the buffered data satisfies match.
+func (b *buffer) Match(match func(data string) bool) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return match(b.data.String())
+}
+
+// Overflowed reports whether the response exceeded limit bytes since
+// the last Capture or Flush.
+func (b *buffer) Overflowed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.overflowed
+}
+
+// Dropped returns the total number of bytes discarded because of limit.
+func (b *buffer) Dropped() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.dropped
+}
+
+// Flush returns the buffered data and resets the buffer. It also reports
+// whether the data exceeded limit bytes.
+func (b *buffer) Flush() (string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	data, overflowed := b.data.String(), b.overflowed
+	b.data.Reset()
+	b.overflowed = false
+
+	return data, overflowed
+}
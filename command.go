@@ -0,0 +1,46 @@
+package telnet
+
+import "strings"
+
+// invalidCommandChars are the characters which are not allowed in commands.
+// CR and LF end the command and NUL may truncate it.
+const invalidCommandChars = "\r\n\x00"
+
+// BuildCommand builds a command line from the command name and separate
+// arguments, for example a chat message passed to "say". Arguments
+// containing spaces are enclosed in double quotes. The console doesn't
+// support escaping, so ErrCommandInvalid is returned if name or arguments
+// contain double quotes, CR, LF or NUL characters.
+func BuildCommand(name string, args ...string) (string, error) {
+	if name == "" {
+		return "", ErrCommandEmpty
+	}
+
+	if strings.ContainsAny(name, invalidCommandChars+"\" \t") {
+		return "", ErrCommandInvalid
+	}
+
+	var b strings.Builder
+
+	b.WriteString(name)
+
+	for _, arg := range args {
+		if strings.ContainsAny(arg, invalidCommandChars+"\"") {
+			return "", ErrCommandInvalid
+		}
+
+		b.WriteByte(' ')
+
+		if arg == "" || strings.ContainsAny(arg, " \t") {
+			b.WriteString(`"` + arg + `"`)
+		} else {
+			b.WriteString(arg)
+		}
+	}
+
+	if b.Len() > MaxCommandLen {
+		return "", ErrCommandTooLong
+	}
+
+	return b.String(), nil
+}
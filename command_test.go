@@ -0,0 +1,44 @@
+package telnet_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gorcon/telnet"
+)
+
+func TestBuildCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		args    []string
+		want    string
+		err     error
+	}{
+		{name: "no args", command: "help", want: "help"},
+		{name: "args", command: "kick", args: []string{"Steam_1", "bye"}, want: "kick Steam_1 bye"},
+		{name: "spaces", command: "say", args: []string{"hello world"}, want: `say "hello world"`},
+		{name: "empty arg", command: "say", args: []string{""}, want: `say ""`},
+		{name: "empty name", command: "", err: telnet.ErrCommandEmpty},
+		{name: "name with space", command: "say hi", err: telnet.ErrCommandInvalid},
+		{name: "crlf", command: "say", args: []string{"hi\r\nshutdown"}, err: telnet.ErrCommandInvalid},
+		{name: "lf", command: "say", args: []string{"hi\nshutdown"}, err: telnet.ErrCommandInvalid},
+		{name: "nul", command: "say", args: []string{"hi\x00"}, err: telnet.ErrCommandInvalid},
+		{name: "quote", command: "say", args: []string{`hi" shutdown "`}, err: telnet.ErrCommandInvalid},
+		{name: "too long", command: "say", args: []string{strings.Repeat("a", telnet.MaxCommandLen)}, err: telnet.ErrCommandTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := telnet.BuildCommand(tt.command, tt.args...)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got err %q, want %v", err, tt.err)
+			}
+
+			if got != tt.want {
+				t.Errorf("got command %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package telnet
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ResponseErrorPrefix is the prefix of the console error lines, for example
+// "*** ERROR: unknown command 'x'".
+const ResponseErrorPrefix = "*** ERROR: "
+
+var (
+	// ErrUnknownCommand is returned when the server doesn't know
+	// the executed command.
+	ErrUnknownCommand = errors.New("unknown command")
+
+	// ErrCommandFailed is returned when the server reported an error
+	// or an exception while executing the command.
+	ErrCommandFailed = errors.New("command failed")
+)
+
+// CommandError is returned by Execute when the response reports a console
+// error. It matches ErrUnknownCommand or ErrCommandFailed with errors.Is.
+// The whole response is still returned by Execute along with the error.
+type CommandError struct {
+	Command string
+
+	// Message is the error text without ResponseErrorPrefix or the log
+	// line prefix, for example "unknown command 'x'".
+	Message string
+
+	err error
+}
+
+// Error implements error interface.
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("telnet: command %q: %s", e.Command, e.Message)
+}
+
+// Unwrap returns ErrUnknownCommand or ErrCommandFailed.
+func (e *CommandError) Unwrap() error {
+	return e.err
+}
+
+// commandError returns *CommandError if response to command reports
+// a console error, see errorScanner. Otherwise, it returns nil.
+func commandError(dialect Dialect, command string, response string) error {
+	scanner := errorScanner{dialect: dialect, command: command}
+
+	for _, raw := range strings.Split(response, "\n") {
+		scanner.scan(raw)
+	}
+
+	return scanner.err()
+}
+
+// errorScanner detects a console error in the response lines to command.
+// Only the lines after the command echo are checked, the lines before it
+// belong to the server log. An exception is the command error only if it
+// directly follows the echo, the later ones are background log lines.
+// If there is no echo, only "*** ERROR:" lines are checked, the server
+// answers unknown commands with them.
+type errorScanner struct {
+	dialect Dialect
+	command string
+	echoed  bool
+	next    bool
+	found   error
+	early   error
+}
+
+// scan checks the next response line.
+func (s *errorScanner) scan(raw string) {
+	raw = strings.TrimRight(raw, "\r")
+
+	if !s.echoed {
+		if s.dialect.IsEcho(raw, s.command) {
+			s.echoed, s.next = true, true
+		} else if s.early == nil {
+			s.early = errorLine(s.command, raw)
+		}
+
+		return
+	}
+
+	next := s.next
+	s.next = false
+
+	if s.found != nil {
+		return
+	}
+
+	if s.found = errorLine(s.command, raw); s.found != nil || !next {
+		return
+	}
+
+	if line := ParseLogLine(raw); line.Level == LevelException {
+		s.found = &CommandError{Command: s.command, Message: line.Message, err: ErrCommandFailed}
+	}
+}
+
+// err returns the detected console error or nil.
+func (s *errorScanner) err() error {
+	if s.echoed {
+		return s.found
+	}
+
+	return s.early
+}
+
+// errorLine returns *CommandError if raw starts with ResponseErrorPrefix.
+func errorLine(command string, raw string) error {
+	message, ok := strings.CutPrefix(strings.TrimLeft(raw, " \t"), ResponseErrorPrefix)
+	if !ok {
+		return nil
+	}
+
+	err := ErrCommandFailed
+	if strings.HasPrefix(message, "unknown command") {
+		err = ErrUnknownCommand
+	}
+
+	return &CommandError{Command: command, Message: message, err: err}
+}
@@ -0,0 +1,277 @@
+package telnet
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AuthResult is the result of authentication detected by Dialect.
+type AuthResult int
+
+const (
+	// AuthUnexpected is returned when the response to the password is not
+	// recognised.
+	AuthUnexpected AuthResult = iota
+
+	// AuthSucceeded is returned when the server accepted the password.
+	AuthSucceeded
+
+	// AuthFailed is returned when the server rejected the password.
+	AuthFailed
+
+	// AuthLockedOut is returned when the server refuses to authenticate
+	// because of too many failed attempts.
+	AuthLockedOut
+)
+
+// Dialect describes the console messages of a game server.
+type Dialect interface {
+	// IsPasswordPrompt reports whether data received after connecting
+	// contains the password prompt.
+	IsPasswordPrompt(data string) bool
+
+	// AuthResult detects the result of authentication from the response
+	// to the password.
+	AuthResult(response string) AuthResult
+
+	// Banner returns the server banner from the response to the password
+	// after successful authentication, see Conn.Status.
+	Banner(response string) string
+
+	// IsEcho reports whether line is the server echo of command, see
+	// SetClearResponse.
+	IsEcho(line string, command string) bool
+
+	// IsOutputStart reports whether line received after command was sent
+	// begins the command output. The lines before it are not waited for
+	// by FramingQuiet since they may belong to the server log.
+	IsOutputStart(line string, command string) bool
+
+	// Framing returns the framing used unless SetFraming is given.
+	// FramingSentinel requires the console to answer SentinelCommand with
+	// the line containing it.
+	Framing() Framing
+}
+
+// SevenDaysDialect is the dialect of 7 Days to Die English console. It is
+// used by default.
+type SevenDaysDialect struct{}
+
+// IsPasswordPrompt implements Dialect interface.
+func (SevenDaysDialect) IsPasswordPrompt(data string) bool {
+	return strings.Contains(data, ResponseEnterPassword)
+}
+
+// AuthResult implements Dialect interface.
+func (SevenDaysDialect) AuthResult(response string) AuthResult {
+	switch {
+	case strings.Contains(response, ResponseAuthTooManyFails):
+		return AuthLockedOut
+	case strings.Contains(response, ResponseAuthIncorrectPassword):
+		return AuthFailed
+	case strings.Contains(response, ResponseAuthSuccess):
+		return AuthSucceeded
+	default:
+		return AuthUnexpected
+	}
+}
+
+// Banner implements Dialect interface.
+func (SevenDaysDialect) Banner(response string) string {
+	response = strings.TrimPrefix(response, ResponseEnterPassword+CRLF+ResponseAuthSuccess)
+	response = strings.TrimSuffix(response, CRLF+CRLF+ResponseWelcome)
+
+	return strings.TrimSpace(response)
+}
+
+// IsEcho implements Dialect interface. The server logs the command with
+// the client address, which may differ from the local one, so only
+// the beginning of the line is matched.
+func (SevenDaysDialect) IsEcho(line string, command string) bool {
+	return strings.Contains(line, fmt.Sprintf(ResponseINFLayout, command, ""))
+}
+
+// IsOutputStart implements Dialect interface. The server answers unknown
+// commands with the error line without echo.
+func (d SevenDaysDialect) IsOutputStart(line string, command string) bool {
+	return d.IsEcho(line, command) || strings.HasPrefix(line, ResponseErrorPrefix)
+}
+
+// Framing implements Dialect interface. The server answers SentinelCommand
+// with the unknown command error.
+func (SevenDaysDialect) Framing() Framing {
+	return FramingSentinel
+}
+
+// GenericDialect is the dialect of line-oriented consoles. Messages are
+// matched case-insensitively. The response to the password is considered
+// failed if the password prompt is its last line or if the message right
+// after the password matches Failure. Otherwise it is successful if
+// the response matches Success, or if Success is not set. The lines after
+// the password prompt are the banner. The echo is the line equal
+// to the command.
+type GenericDialect struct {
+	// PasswordPrompt is the part of the password prompt. The default is
+	// "password".
+	PasswordPrompt string
+
+	// Success are the parts of the messages about the accepted password.
+	// If set, the response matching none of them is unexpected.
+	Success []string
+
+	// Failure are the parts of the messages about the wrong password.
+	// The default is "incorrect", "invalid", "wrong" and "denied".
+	Failure []string
+
+	// Lockout are the parts of the messages about too many failed
+	// attempts. The default is "too many".
+	Lockout []string
+}
+
+// IsPasswordPrompt implements Dialect interface.
+func (d GenericDialect) IsPasswordPrompt(data string) bool {
+	return containsAnyFold(data, []string{d.prompt()})
+}
+
+// AuthResult implements Dialect interface.
+func (d GenericDialect) AuthResult(response string) AuthResult {
+	lockout := d.Lockout
+	if lockout == nil {
+		lockout = []string{"too many"}
+	}
+
+	failure := d.Failure
+	if failure == nil {
+		failure = []string{"incorrect", "invalid", "wrong", "denied"}
+	}
+
+	message, reprompt := d.authMessage(response)
+
+	switch {
+	case containsAnyFold(message, lockout):
+		return AuthLockedOut
+	case reprompt, containsAnyFold(message, failure):
+		return AuthFailed
+	case d.Success != nil && !containsAnyFold(response, d.Success):
+		return AuthUnexpected
+	default:
+		return AuthSucceeded
+	}
+}
+
+// authMessage returns the message right after the password, so the words
+// of the banner are not taken as the failure. After the failure the console
+// may ask the password again, reprompt is true then and all the lines
+// before the prompt are the message. Otherwise, the message is the first
+// sentence of the first line.
+func (d GenericDialect) authMessage(response string) (string, bool) {
+	var lines []string
+
+	for _, line := range strings.Split(response, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	// The prompt received before the password may lead the response.
+	if len(lines) > 1 && d.isPromptLine(lines[0]) {
+		lines = lines[1:]
+	}
+
+	if len(lines) == 0 {
+		return "", false
+	}
+
+	if last := len(lines) - 1; d.isPromptLine(lines[last]) {
+		return strings.Join(lines[:last], "\n"), true
+	}
+
+	if i := strings.IndexAny(lines[0], ".!"); i != -1 {
+		return lines[0][:i], false
+	}
+
+	return lines[0], false
+}
+
+// Banner implements Dialect interface.
+func (d GenericDialect) Banner(response string) string {
+	lines := strings.Split(response, "\n")
+
+	for i := len(lines) - 1; i >= 0; i-- {
+		if d.isPromptLine(lines[i]) {
+			lines = lines[i+1:]
+
+			break
+		}
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// isPromptLine reports whether line is the password prompt, that is it ends
+// with PasswordPrompt optionally followed by a colon or an angle bracket.
+// The lines only mentioning the password are not prompts.
+func (d GenericDialect) isPromptLine(line string) bool {
+	line = strings.ToLower(strings.TrimSpace(line))
+	prompt := strings.ToLower(d.prompt())
+
+	return strings.HasSuffix(line, prompt) || strings.HasSuffix(strings.TrimRight(line, ":> "), prompt)
+}
+
+// prompt returns PasswordPrompt or the default one.
+func (d GenericDialect) prompt() string {
+	if d.PasswordPrompt == "" {
+		return "password"
+	}
+
+	return d.PasswordPrompt
+}
+
+// IsEcho implements Dialect interface.
+func (GenericDialect) IsEcho(line string, command string) bool {
+	return strings.TrimSpace(line) == command
+}
+
+// IsOutputStart implements Dialect interface. The console may not echo
+// the command, any line begins the output.
+func (GenericDialect) IsOutputStart(string, string) bool {
+	return true
+}
+
+// Framing implements Dialect interface. The answer to SentinelCommand
+// is unknown, FramingQuiet is used.
+func (GenericDialect) Framing() Framing {
+	return FramingQuiet
+}
+
+// containsAnyFold reports whether s contains any of substrs ignoring case.
+func containsAnyFold(s string, substrs []string) bool {
+	s = strings.ToLower(s)
+
+	for _, substr := range substrs {
+		if substr != "" && strings.Contains(s, strings.ToLower(substr)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cutEcho returns the part of response after the command echo line.
+// If there is no echo, response is returned as is.
+func cutEcho(dialect Dialect, command string, response string) string {
+	for rest := response; rest != ""; {
+		line, next, found := strings.Cut(rest, "\n")
+		if dialect.IsEcho(strings.TrimRight(line, "\r"), command) {
+			return next
+		}
+
+		if !found {
+			break
+		}
+
+		rest = next
+	}
+
+	return response
+}
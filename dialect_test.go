@@ -0,0 +1,174 @@
+package telnet_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorcon/telnet"
+	"github.com/gorcon/telnet/telnettest"
+)
+
+func TestDialect_AuthResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  telnet.Dialect
+		response string
+		want     telnet.AuthResult
+	}{
+		{name: "7dtd success", dialect: telnet.SevenDaysDialect{}, response: telnet.ResponseAuthSuccess, want: telnet.AuthSucceeded},
+		{name: "7dtd failure", dialect: telnet.SevenDaysDialect{}, response: telnet.ResponseAuthIncorrectPassword, want: telnet.AuthFailed},
+		{name: "7dtd lockout", dialect: telnet.SevenDaysDialect{}, response: telnet.ResponseAuthTooManyFails, want: telnet.AuthLockedOut},
+		{name: "7dtd unexpected", dialect: telnet.SevenDaysDialect{}, response: "My spoon is too big", want: telnet.AuthUnexpected},
+		{name: "generic success", dialect: telnet.GenericDialect{}, response: "Welcome, admin", want: telnet.AuthSucceeded},
+		{name: "generic failure", dialect: telnet.GenericDialect{}, response: "Access DENIED", want: telnet.AuthFailed},
+		{name: "generic lockout", dialect: telnet.GenericDialect{}, response: "Too many attempts, access denied", want: telnet.AuthLockedOut},
+		{name: "generic custom", dialect: telnet.GenericDialect{Failure: []string{"nope"}}, response: "nope", want: telnet.AuthFailed},
+		{name: "generic prompt again", dialect: telnet.GenericDialect{}, response: "Password:", want: telnet.AuthFailed},
+		{name: "generic prompt before banner", dialect: telnet.GenericDialect{}, response: "Password:\r\nWelcome, admin", want: telnet.AuthSucceeded},
+		{name: "generic password in banner", dialect: telnet.GenericDialect{}, response: "Password:\r\nWelcome! Type 'passwd' to change your password.", want: telnet.AuthSucceeded},
+		{name: "generic failure word in banner", dialect: telnet.GenericDialect{}, response: "Password:\r\nLogged in. Invalid commands are logged.", want: telnet.AuthSucceeded},
+		{name: "generic failure before prompt", dialect: telnet.GenericDialect{}, response: "Login incorrect\r\nPassword: ", want: telnet.AuthFailed},
+		{name: "generic success marker", dialect: telnet.GenericDialect{Success: []string{"welcome"}}, response: "Welcome, admin", want: telnet.AuthSucceeded},
+		{name: "generic no success marker", dialect: telnet.GenericDialect{Success: []string{"welcome"}}, response: "Server is starting", want: telnet.AuthUnexpected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.dialect.AuthResult(tt.response); got != tt.want {
+				t.Errorf("got result %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetDialect(t *testing.T) {
+	t.Run("generic", func(t *testing.T) {
+		server := telnettest.NewServer(
+			telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+			telnettest.SetAuthHandler(func(c *telnettest.Context) {
+				if c.Request() == c.Server().Settings.Password {
+					c.Writer().WriteString("Welcome, admin" + telnet.CRLF)
+
+					c.Auth.Success = true
+					c.Auth.Break = true
+
+					return
+				}
+
+				if strings.HasPrefix(c.Request(), "again") {
+					// The console asks again without a failure message.
+					c.Writer().WriteString(telnet.ResponseEnterPassword + telnet.CRLF)
+
+					return
+				}
+
+				c.Writer().WriteString("Access denied" + telnet.CRLF)
+			}),
+			telnettest.SetCommandHandler(func(c *telnettest.Context) {
+				if c.Request() == "" {
+					return
+				}
+
+				// The console echoes the command line.
+				c.Writer().WriteString(c.Request() + telnet.CRLF)
+				c.Writer().WriteString("ok" + telnet.CRLF)
+				c.Writer().Flush()
+			}),
+		)
+		defer server.Close()
+
+		if _, err := telnet.Dial(server.Addr(), "wrong", telnet.SetDialect(telnet.GenericDialect{})); !errors.Is(err, telnet.ErrAuthFailed) {
+			t.Fatalf("got err %q, want %q", err, telnet.ErrAuthFailed)
+		}
+
+		if _, err := telnet.Dial(server.Addr(), "again", telnet.SetDialect(telnet.GenericDialect{})); !errors.Is(err, telnet.ErrAuthFailed) {
+			t.Fatalf("got err %q, want %q", err, telnet.ErrAuthFailed)
+		}
+
+		conn, err := telnet.Dial(server.Addr(), "password",
+			telnet.SetDialect(telnet.GenericDialect{}), telnet.SetClearResponse(true))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if conn.Status() != "Welcome, admin" {
+			t.Errorf("got status %q, want %q", conn.Status(), "Welcome, admin")
+		}
+
+		result, err := conn.Execute("status")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "ok" {
+			t.Errorf("got result %q, want %q", result, "ok")
+		}
+	})
+
+	t.Run("generic without echo", func(t *testing.T) {
+		server := telnettest.NewServer(
+			telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+			telnettest.SetAuthHandler(func(c *telnettest.Context) {
+				c.Writer().WriteString("Welcome, admin" + telnet.CRLF)
+
+				c.Auth.Success = true
+				c.Auth.Break = true
+			}),
+			telnettest.SetCommandHandler(func(c *telnettest.Context) {
+				switch c.Request() {
+				case "":
+					return
+				case "status":
+					c.Writer().WriteString("ok" + telnet.CRLF)
+				default:
+					c.Writer().WriteString("Unknown command." + telnet.CRLF)
+				}
+
+				c.Writer().Flush()
+			}),
+		)
+		defer server.Close()
+
+		conn, err := telnet.Dial(server.Addr(), "password",
+			telnet.SetDialect(telnet.GenericDialect{}), telnet.SetExecuteTimeout(time.Second))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		for i := 0; i < 2; i++ {
+			result, err := conn.Execute("status")
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			if result != "ok" {
+				t.Errorf("got result %q, want %q", result, "ok")
+			}
+		}
+	})
+
+	t.Run("lockout", func(t *testing.T) {
+		server := telnettest.NewServer(
+			telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+			telnettest.SetAuthHandler(func(c *telnettest.Context) {
+				c.Writer().WriteString(telnet.ResponseAuthTooManyFails + telnet.CRLF)
+
+				c.Auth.Break = true
+			}),
+		)
+		defer server.Close()
+
+		_, err := telnet.Dial(server.Addr(), "password")
+		if !errors.Is(err, telnet.ErrAuthLockedOut) {
+			t.Fatalf("got err %q, want %q", err, telnet.ErrAuthLockedOut)
+		}
+
+		if !errors.Is(err, telnet.ErrAuthFailed) {
+			t.Fatalf("got err %q, want %q", err, telnet.ErrAuthFailed)
+		}
+	})
+}
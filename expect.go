@@ -0,0 +1,67 @@
+package telnet
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+// Expect blocks until a line matching re is received from the server and
+// returns the match as regexp.FindStringSubmatch does. Both log lines and
+// command response lines are matched, the lines are still delivered
+// to Execute callers and subscribers. Expect returns the wrapped ctx.Err()
+// if the context is done first.
+func (c *Conn) Expect(ctx context.Context, re *regexp.Regexp) ([]string, error) {
+	matches, stop := c.match(re)
+	defer stop()
+
+	return c.expect(ctx, matches)
+}
+
+// SendAndExpect executes command and blocks until a line matching re is
+// received from the server, see Expect. The lines of the command response
+// are matched too. It is useful for the commands which report completion
+// later in the log, such as "saveworld".
+func (c *Conn) SendAndExpect(ctx context.Context, command string, re *regexp.Regexp) ([]string, error) {
+	matches, stop := c.match(re)
+	defer stop()
+
+	if _, err := c.executeCommand(ctx, command, nil); err != nil {
+		return nil, err
+	}
+
+	return c.expect(ctx, matches)
+}
+
+// match matches the received lines against re as they are received until
+// the returned function is called. The first match is sent to the returned
+// channel.
+func (c *Conn) match(re *regexp.Regexp) (<-chan []string, func()) {
+	matches := make(chan []string, 1)
+	matched := false
+
+	stop := c.logs.watch(func(raw string) {
+		if matched {
+			return
+		}
+
+		if match := re.FindStringSubmatch(raw); match != nil {
+			matched = true
+			matches <- match
+		}
+	})
+
+	return matches, stop
+}
+
+// expect waits for the match.
+func (c *Conn) expect(ctx context.Context, matches <-chan []string) ([]string, error) {
+	select {
+	case match := <-matches:
+		return match, nil
+	case <-c.Done():
+		return nil, c.Err()
+	case <-ctx.Done():
+		return nil, fmt.Errorf("telnet: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,99 @@
+package telnet_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gorcon/telnet"
+	"github.com/gorcon/telnet/telnettest"
+)
+
+func TestConn_Expect(t *testing.T) {
+	server := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+		telnettest.SetAuthHandler(authHandler),
+		telnettest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	conn, err := telnet.Dial(server.Addr(), "password", telnet.SetFraming(telnet.FramingQuiet))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	t.Run("log line", func(t *testing.T) {
+		sub := conn.Subscribe()
+		defer sub.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		match, err := conn.SendAndExpect(ctx, "chat", regexp.MustCompile(`'Player': hello (\d)`))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if len(match) != 2 || match[1] != "1" {
+			t.Fatalf("got match %q, want %q", match, "1")
+		}
+
+		// The matched line is still delivered to subscribers.
+		select {
+		case line := <-sub.Lines():
+			if line.Message != "Chat (from 'Steam_1', entity id '171', to 'Global'): 'Player': hello 1" {
+				t.Errorf("got line %q, want hello 1 chat message", line.Message)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("got no line, want the matched line to be delivered to subscribers")
+		}
+	})
+
+	t.Run("response line", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		match, err := conn.SendAndExpect(ctx, "help", regexp.MustCompile(`^lorem (\w+)`))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if len(match) != 2 || match[1] != "ipsum" {
+			t.Fatalf("got match %q, want %q", match, "ipsum")
+		}
+	})
+
+	t.Run("long response", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		match, err := conn.SendAndExpect(ctx, "marked", regexp.MustCompile(`MARK (\w+)`))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if len(match) != 2 || match[1] != "done" {
+			t.Fatalf("got match %q, want %q", match, "done")
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err := conn.Expect(ctx, regexp.MustCompile(`never`))
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("got err %q, want %q", err, context.DeadlineExceeded)
+		}
+	})
+
+	t.Run("command error", func(t *testing.T) {
+		_, err := conn.SendAndExpect(context.Background(), "random", regexp.MustCompile(`never`))
+		if !errors.Is(err, telnet.ErrUnknownCommand) {
+			t.Fatalf("got err %q, want %q", err, telnet.ErrUnknownCommand)
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package telnet
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Framing defines the way Conn detects the end of a command response.
+type Framing int
+
+const (
+	// FramingQuiet considers the response complete when the command output
+	// started (see Dialect.IsOutputStart) and no more data arrived during
+	// the quiet period (see SetQuietPeriod). The commands pausing longer than the quiet period
+	// are cut off, the rest of their response is taken by the next command.
+	FramingQuiet Framing = iota
+
+	// FramingSentinel sends SentinelCommand right after the command and
+	// considers the response complete as soon as the server answered
+	// the sentinel, the quiet period is not waited. It handles slow commands,
+	// but leaves the sentinel in the server log.
+	// If the response exceeds the maximum buffer size, it falls back to
+	// FramingQuiet. It is used by default with SevenDaysDialect.
+	FramingSentinel
+
+	// FramingSleep waits ExecuteTickTimeout and takes whatever was received.
+	// This is the behaviour of the previous versions.
+	FramingSleep
+)
+
+// framingDialect is replaced with the framing of the dialect, see
+// Dialect.Framing.
+const framingDialect Framing = -1
+
+// wait blocks until the response to the command sent at the sent time
+// is complete according to framing. If it doesn't happen during the execute
+// timeout, ErrResponseTimeout is returned. The streamed response may take
+// longer, the timeout counts from the last received line then. If
+// the connection is lost before the response is complete, ErrConnectionLost
+// is returned.
+func (c *Conn) wait(ctx context.Context, framing Framing, sent time.Time) error {
+	timeout := c.settings.executeTimeout
+	if framing == FramingSleep {
+		timeout = ExecuteTickTimeout
+	}
+
+	timer := time.NewTimer(time.Until(sent.Add(timeout)))
+	defer timer.Stop()
+
+	ticker := time.NewTicker(ReceiveWaitPeriod)
+	defer ticker.Stop()
+
+	done := c.done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("telnet: %w", ctx.Err())
+		case <-timer.C:
+			if framing == FramingSleep {
+				// Take whatever was received.
+				return nil
+			}
+
+			if received := c.buffer.Received(); c.buffer.Streaming() && received.After(sent) {
+				if d := time.Until(received.Add(timeout)); d > 0 {
+					timer.Reset(d)
+
+					continue
+				}
+			}
+
+			return fmt.Errorf("telnet: %w", ErrResponseTimeout)
+		case <-done:
+			// Nothing more will be received. The response without
+			// the sentinel answer is cut off.
+			if c.buffer.Received().Before(sent) || (framing == FramingSentinel && !c.buffer.Sentinel()) {
+				return fmt.Errorf("telnet: %w", ErrConnectionLost)
+			}
+
+			return nil
+		case <-ticker.C:
+		}
+
+		if framing == FramingSleep {
+			continue
+		}
+
+		received := c.buffer.Received()
+		if received.Before(sent) {
+			continue
+		}
+
+		if framing == FramingSentinel && c.buffer.Sentinel() {
+			// The answer to the sentinel is the last line of the response.
+			return nil
+		}
+
+		if time.Since(received) < c.settings.quietPeriod {
+			continue
+		}
+
+		switch {
+		case framing == FramingSentinel && !c.buffer.Overflowed():
+			// The sentinel may be discarded on overflow, fall back to
+			// the quiet period then.
+			continue
+		case !c.buffer.Echoed():
+			// The lines received before the command output may belong to
+			// the server log, the quiet period starts after it.
+			continue
+		}
+
+		return nil
+	}
+}
+
+// waitFor blocks until the received data satisfies match. If it doesn't
+// happen during the execute timeout, waitFor returns without error to let
+// the caller handle whatever was received.
+func (c *Conn) waitFor(ctx context.Context, match func(data string) bool) error {
+	timer := time.NewTimer(c.settings.executeTimeout)
+	defer timer.Stop()
+
+	ticker := time.NewTicker(ReceiveWaitPeriod)
+	defer ticker.Stop()
+
+	done := c.done()
+
+	for !c.buffer.Match(match) {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("telnet: %w", ctx.Err())
+		case <-timer.C:
+			return nil
+		case <-done:
+			return nil
+		case <-ticker.C:
+		}
+	}
+
+	return nil
+}
+
+// cutSentinel removes the server answer to SentinelCommand from response.
+func cutSentinel(response string) string {
+	i := strings.Index(response, SentinelCommand)
+	if i == -1 {
+		return response
+	}
+
+	// Cut the whole line containing the sentinel.
+	return response[:strings.LastIndex(response[:i], "\n")+1]
+}
@@ -0,0 +1,122 @@
+package telnet
+
+import (
+	"slices"
+	"strings"
+)
+
+// CommandHelp is the command which lists the server commands.
+const CommandHelp = "help"
+
+// helpCommandsHeader starts the list of commands in the help response.
+const helpCommandsHeader = "*** List of Commands ***"
+
+// CommandInfo is a server command listed by help command.
+type CommandInfo struct {
+	Name    string
+	Aliases []string
+
+	// Description may contain several lines separated by "\n".
+	Description string
+}
+
+// Commands executes help command and returns the server commands. The result
+// is cached until the connection is restored, see SetReconnect, since
+// the server may be restarted with other mods.
+func (c *Conn) Commands() ([]CommandInfo, error) {
+	c.commandsMu.Lock()
+	defer c.commandsMu.Unlock()
+
+	// The number of reconnects is the version of the cache. It is not
+	// reset by the reconnection goroutine, which may wait for the help
+	// command executed here.
+	reconnects := c.stats.reconnects.Load()
+
+	if c.commands != nil && c.commandsReconnects == reconnects {
+		return slices.Clone(c.commands), nil
+	}
+
+	response, err := c.Execute(CommandHelp)
+	if err != nil {
+		return nil, err
+	}
+
+	commands, err := ParseCommands(response)
+	if err != nil {
+		return nil, err
+	}
+
+	c.commands, c.commandsReconnects = commands, reconnects
+
+	return slices.Clone(commands), nil
+}
+
+// ParseCommands parses help command response. If the response contains
+// "*** List of Commands ***" header, only the lines after it are parsed.
+func ParseCommands(response string) ([]CommandInfo, error) {
+	lines := strings.Split(response, "\n")
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) == helpCommandsHeader {
+			lines = lines[i+1:]
+
+			break
+		}
+	}
+
+	commands := make([]CommandInfo, 0)
+
+	for _, line := range lines {
+		line = strings.TrimRight(line, "\r ")
+
+		names, description, ok := strings.Cut(line, " => ")
+		if !ok {
+			names, ok = strings.CutSuffix(line, " =>")
+		}
+
+		if !ok {
+			// The description may span several lines.
+			if len(commands) > 0 && line != "" && !strings.HasPrefix(line, "***") {
+				last := &commands[len(commands)-1]
+				last.Description += "\n" + line
+			}
+
+			continue
+		}
+
+		fields := strings.Fields(names)
+		if len(fields) == 0 {
+			continue
+		}
+
+		commands = append(commands, CommandInfo{
+			Name:        fields[0],
+			Aliases:     fields[1:],
+			Description: strings.TrimSpace(description),
+		})
+	}
+
+	if len(commands) == 0 {
+		return nil, ErrUnexpectedResponse
+	}
+
+	return commands, nil
+}
+
+// LookupCommand returns the command which name or alias is name. Commands
+// are case-insensitive.
+func LookupCommand(commands []CommandInfo, name string) (CommandInfo, bool) {
+	for _, command := range commands {
+		if strings.EqualFold(command.Name, name) {
+			return command, true
+		}
+
+		for _, alias := range command.Aliases {
+			if strings.EqualFold(alias, name) {
+				return command, true
+			}
+		}
+	}
+
+	return CommandInfo{}, false
+}
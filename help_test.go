@@ -0,0 +1,138 @@
+package telnet_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorcon/telnet"
+	"github.com/gorcon/telnet/telnettest"
+)
+
+const helpResponse = `*** Generic Console Help ***
+To get further help on a specific topic or command type (without the brackets)
+    help <topic / command>
+
+*** List of Help Topics ***
+None yet
+
+*** List of Commands ***
+ admin => Manage user permission levels
+ chunkcache cc => shows all loaded chunks in cache
+ debugshot dbs => Lets you make a screenshot that will have some generic info
+on it and a custom text you can enter.
+ decomgr => 
+ listplayers lp => lists all players`
+
+func TestParseCommands(t *testing.T) {
+	t.Run("help", func(t *testing.T) {
+		want := []telnet.CommandInfo{
+			{Name: "admin", Aliases: []string{}, Description: "Manage user permission levels"},
+			{Name: "chunkcache", Aliases: []string{"cc"}, Description: "shows all loaded chunks in cache"},
+			{
+				Name: "debugshot", Aliases: []string{"dbs"},
+				Description: "Lets you make a screenshot that will have some generic info\non it and a custom text you can enter.",
+			},
+			{Name: "decomgr", Aliases: []string{}},
+			{Name: "listplayers", Aliases: []string{"lp"}, Description: "lists all players"},
+		}
+
+		got, err := telnet.ParseCommands(strings.ReplaceAll(helpResponse, "\n", telnet.CRLF))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got commands %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("unexpected response", func(t *testing.T) {
+		_, err := telnet.ParseCommands("lorem ipsum dolor sit amet")
+		if !errors.Is(err, telnet.ErrUnexpectedResponse) {
+			t.Fatalf("got err %q, want %q", err, telnet.ErrUnexpectedResponse)
+		}
+	})
+}
+
+func TestLookupCommand(t *testing.T) {
+	commands, err := telnet.ParseCommands(helpResponse)
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	for _, name := range []string{"listplayers", "lp", "LP"} {
+		if command, ok := telnet.LookupCommand(commands, name); !ok || command.Name != "listplayers" {
+			t.Errorf("got command %+v, want listplayers for %q", command, name)
+		}
+	}
+
+	if _, ok := telnet.LookupCommand(commands, "random"); ok {
+		t.Errorf("got command for %q, want not found", "random")
+	}
+}
+
+func TestConn_Commands(t *testing.T) {
+	var helps atomic.Int32
+
+	server := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+		telnettest.SetAuthHandler(authHandler),
+		telnettest.SetCommandHandler(func(c *telnettest.Context) {
+			if c.Request() != telnet.CommandHelp {
+				commandHandler(c)
+
+				return
+			}
+
+			helps.Add(1)
+
+			c.Writer().WriteString(fmt.Sprintf("2020-11-14T23:09:20 31220.643 "+telnet.ResponseINFLayout, c.Request(), c.Conn().RemoteAddr()) + telnet.CRLF)
+			c.Writer().WriteString(strings.ReplaceAll(helpResponse, "\n", telnet.CRLF) + telnet.CRLF)
+			c.Writer().Flush()
+		}),
+	)
+	defer server.Close()
+
+	events := make(chan telnet.ReconnectEvent, 10)
+
+	conn, err := telnet.Dial(server.Addr(), "password",
+		telnet.SetReconnect(10*time.Millisecond, 3),
+		telnet.SetReconnectHandler(func(event telnet.ReconnectEvent) { events <- event }),
+	)
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	for i := 0; i < 2; i++ {
+		commands, err := conn.Commands()
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if len(commands) != 5 {
+			t.Fatalf("got %d commands, want %d", len(commands), 5)
+		}
+	}
+
+	if got := helps.Load(); got != 1 {
+		t.Errorf("got %d help commands executed, want %d", got, 1)
+	}
+
+	// The restarted server may have other commands.
+	server.CloseClientConnections()
+	waitEvent(t, events, telnet.Reconnected)
+
+	if _, err := conn.Commands(); err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if got := helps.Load(); got != 2 {
+		t.Errorf("got %d help commands executed, want %d after reconnect", got, 2)
+	}
+}
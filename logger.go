@@ -0,0 +1,34 @@
+package telnet
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// Redacted replaces the password in logs.
+const Redacted = "[REDACTED]"
+
+// logRecord writes a record to logger if it is set. The password is
+// redacted from string values of args.
+func logRecord(logger *slog.Logger, password string, level slog.Level, msg string, args ...any) {
+	if logger == nil || !logger.Enabled(context.Background(), level) {
+		return
+	}
+
+	if password != "" {
+		// Keys are at even positions, values are at odd positions.
+		for i := 1; i < len(args); i += 2 {
+			if s, ok := args[i].(string); ok {
+				args[i] = strings.ReplaceAll(s, password, Redacted)
+			}
+		}
+	}
+
+	logger.Log(context.Background(), level, msg, args...)
+}
+
+// log writes a record to the configured logger.
+func (c *Conn) log(level slog.Level, msg string, args ...any) {
+	logRecord(c.settings.logger, c.password, level, msg, args...)
+}
@@ -0,0 +1,116 @@
+package telnet
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"time"
+)
+
+// LogTimeLayout is the layout of the time at the beginning of a log line.
+const LogTimeLayout = "2006-01-02T15:04:05"
+
+// LogLevel is the level of a log line.
+type LogLevel string
+
+// Log levels used by the server.
+const (
+	LevelInfo      LogLevel = "INF"
+	LevelWarning   LogLevel = "WRN"
+	LevelError     LogLevel = "ERR"
+	LevelException LogLevel = "EXC"
+)
+
+// logLineRegexp matches lines like
+// "2020-12-07T21:37:00 31123.521 INF Executing command 'help' by Telnet from 127.0.0.1:45678".
+var logLineRegexp = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}) (\d+(?:\.\d+)?) ([A-Z]{3}) (.*)$`)
+
+// LogLine is a line of the server log.
+type LogLine struct {
+	// Time is the server time. The log doesn't contain the time zone,
+	// so the time is returned in UTC.
+	Time time.Time
+
+	// Uptime is the time passed since the server start.
+	Uptime time.Duration
+
+	// Level is empty for lines without prefix.
+	Level LogLevel
+
+	// Message is the line without time, uptime and level prefix.
+	Message string
+
+	// Continuation is true for the lines without prefix which follow
+	// an exception or an error, such as stack trace lines. The level
+	// of such lines is inherited from the line they continue.
+	Continuation bool
+
+	// Raw is the line as it was received.
+	Raw string
+}
+
+// ParseLogLine parses a single log line. The lines without prefix are
+// returned with Message and Raw only. Use LogParser to recognise
+// continuation lines.
+func ParseLogLine(raw string) LogLine {
+	line := LogLine{Message: raw, Raw: raw}
+
+	matches := logLineRegexp.FindStringSubmatch(raw)
+	if matches == nil {
+		return line
+	}
+
+	t, err := time.Parse(LogTimeLayout, matches[1])
+	if err != nil {
+		return line
+	}
+
+	uptime, err := time.ParseDuration(matches[2] + "s")
+	if err != nil {
+		return line
+	}
+
+	line.Time = t
+	line.Uptime = uptime
+	line.Level = LogLevel(matches[3])
+	line.Message = matches[4]
+
+	return line
+}
+
+// LogParser parses consecutive log lines and recognises continuation lines
+// of multi-line exceptions and errors.
+type LogParser struct {
+	last LogLine
+}
+
+// Parse parses the next log line.
+func (p *LogParser) Parse(raw string) LogLine {
+	line := ParseLogLine(raw)
+
+	if line.Level == "" && raw != "" && (p.last.Level == LevelException || p.last.Level == LevelError) {
+		line.Level = p.last.Level
+		line.Time = p.last.Time
+		line.Uptime = p.last.Uptime
+		line.Continuation = true
+	}
+
+	p.last = line
+
+	return line
+}
+
+// ParseLog reads the server log, such as output_log file, from r and calls
+// fn for each parsed line. It stops on the first error returned by fn.
+func ParseLog(r io.Reader, fn func(line LogLine) error) error {
+	var parser LogParser
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if err := fn(parser.Parse(scanner.Text())); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,127 @@
+package telnet_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorcon/telnet"
+)
+
+func TestParseLogLine(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want telnet.LogLine
+	}{
+		{
+			name: "info",
+			raw:  "2020-12-07T21:37:00 31123.521 INF Executing command 'help' by Telnet from 127.0.0.1:45678",
+			want: telnet.LogLine{
+				Time:    time.Date(2020, 12, 7, 21, 37, 0, 0, time.UTC),
+				Uptime:  31123*time.Second + 521*time.Millisecond,
+				Level:   telnet.LevelInfo,
+				Message: "Executing command 'help' by Telnet from 127.0.0.1:45678",
+			},
+		},
+		{
+			name: "warning",
+			raw:  "2020-11-14T23:09:20 5.000 WRN Player is stuck",
+			want: telnet.LogLine{
+				Time:    time.Date(2020, 11, 14, 23, 9, 20, 0, time.UTC),
+				Uptime:  5 * time.Second,
+				Level:   telnet.LevelWarning,
+				Message: "Player is stuck",
+			},
+		},
+		{
+			name: "no prefix",
+			raw:  "*** ERROR: unknown command 'random'",
+			want: telnet.LogLine{Message: "*** ERROR: unknown command 'random'"},
+		},
+		{
+			name: "invalid time",
+			raw:  "2020-13-45T21:37:00 31123.521 INF Message",
+			want: telnet.LogLine{Message: "2020-13-45T21:37:00 31123.521 INF Message"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.want.Raw = tt.raw
+
+			if got := telnet.ParseLogLine(tt.raw); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLog(t *testing.T) {
+	log := "2020-12-07T21:37:00 31123.521 INF Time: 518.02m FPS: 35.10\r\n" +
+		"2020-12-07T21:37:01 31124.000 EXC Object reference not set to an instance of an object\r\n" +
+		"  at GameManager.Update () [0x00000] in <00000000000000000000000000000000>:0 \r\n" +
+		"  at World.Tick () [0x00000] in <00000000000000000000000000000000>:0 \r\n" +
+		"2020-12-07T21:37:02 31125.000 INF Chat (from '-non-player-', entity id '-1', to 'Global'): 'Server': 10\r\n" +
+		"free text\r\n"
+
+	t.Run("parse", func(t *testing.T) {
+		var lines []telnet.LogLine
+
+		err := telnet.ParseLog(strings.NewReader(log), func(line telnet.LogLine) error {
+			lines = append(lines, line)
+
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if len(lines) != 6 {
+			t.Fatalf("got %d lines, want %d", len(lines), 6)
+		}
+
+		want := []struct {
+			level        telnet.LogLevel
+			continuation bool
+		}{
+			{telnet.LevelInfo, false},
+			{telnet.LevelException, false},
+			{telnet.LevelException, true},
+			{telnet.LevelException, true},
+			{telnet.LevelInfo, false},
+			{"", false},
+		}
+
+		for i, w := range want {
+			if lines[i].Level != w.level || lines[i].Continuation != w.continuation {
+				t.Errorf("line %d: got level %q continuation %v, want %q %v",
+					i, lines[i].Level, lines[i].Continuation, w.level, w.continuation)
+			}
+		}
+
+		if lines[2].Uptime != lines[1].Uptime {
+			t.Errorf("got continuation uptime %s, want %s", lines[2].Uptime, lines[1].Uptime)
+		}
+	})
+
+	t.Run("callback error", func(t *testing.T) {
+		errStop := errors.New("stop")
+		calls := 0
+
+		err := telnet.ParseLog(strings.NewReader(log), func(line telnet.LogLine) error {
+			calls++
+
+			return errStop
+		})
+		if !errors.Is(err, errStop) {
+			t.Errorf("got err %q, want %q", err, errStop)
+		}
+
+		if calls != 1 {
+			t.Errorf("got %d calls, want %d", calls, 1)
+		}
+	})
+}
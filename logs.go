@@ -0,0 +1,184 @@
+package telnet
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultLogBufferSize provides default capacity of Subscription channel.
+const DefaultLogBufferSize = 100
+
+// OverflowPolicy defines what to do with new data when a buffer is full.
+type OverflowPolicy int
+
+const (
+	// DropNewest discards the new data.
+	DropNewest OverflowPolicy = iota
+
+	// DropOldest discards the oldest data to make room for the new data.
+	DropOldest
+
+	// FailOnOverflow discards the new data like DropNewest and makes Execute
+	// return ErrResponseTooLarge. Subscriptions treat it as DropNewest.
+	FailOnOverflow
+)
+
+// Subscription delivers the lines received from the server outside command
+// responses and the log lines received during them, see Conn.Subscribe.
+type Subscription struct {
+	hub     *logHub
+	lines   chan LogLine
+	dropped atomic.Uint64
+}
+
+// Lines returns the channel the lines are delivered to. The channel is closed
+// when the subscription or the connection is closed.
+func (s *Subscription) Lines() <-chan LogLine {
+	return s.lines
+}
+
+// Dropped returns the number of lines discarded because the channel was full.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close stops the delivery and closes the Lines channel.
+func (s *Subscription) Close() {
+	s.hub.unsubscribe(s)
+}
+
+// send delivers line according to the overflow policy.
+func (s *Subscription) send(line LogLine, policy OverflowPolicy) {
+	for {
+		select {
+		case s.lines <- line:
+			return
+		default:
+		}
+
+		if policy != DropOldest || cap(s.lines) == 0 {
+			s.dropped.Add(1)
+
+			return
+		}
+
+		select {
+		case <-s.lines:
+			s.dropped.Add(1)
+		default:
+		}
+	}
+}
+
+// logHub delivers log lines to all subscribers and watchers.
+type logHub struct {
+	mu       sync.Mutex
+	subs     map[*Subscription]struct{}
+	watchers map[*watcher]struct{}
+	size     int
+	policy   OverflowPolicy
+	parser   LogParser
+	closed   bool
+}
+
+// watcher receives every line including the lines of command responses.
+type watcher struct {
+	fn func(raw string)
+}
+
+// newLogHub creates a new logHub with subscriptions configured by settings.
+func newLogHub(settings Settings) *logHub {
+	return &logHub{
+		subs:     make(map[*Subscription]struct{}),
+		watchers: make(map[*watcher]struct{}),
+		size:     settings.logBufferSize,
+		policy:   settings.logOverflow,
+	}
+}
+
+// subscribe creates a new subscription. If the hub is closed, the returned
+// subscription is closed too.
+func (h *logHub) subscribe() *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := &Subscription{hub: h, lines: make(chan LogLine, h.size)}
+	if h.closed {
+		close(s.lines)
+
+		return s
+	}
+
+	h.subs[s] = struct{}{}
+
+	return s
+}
+
+// unsubscribe removes s from the hub and closes its channel.
+func (h *logHub) unsubscribe(s *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subs[s]; !ok {
+		return
+	}
+
+	delete(h.subs, s)
+	close(s.lines)
+}
+
+// watch calls fn for every received line until the returned function is
+// called. fn is called from the reader goroutine and must not block.
+func (h *logHub) watch(fn func(raw string)) func() {
+	w := &watcher{fn: fn}
+
+	h.mu.Lock()
+	h.watchers[w] = struct{}{}
+	h.mu.Unlock()
+
+	return func() {
+		h.mu.Lock()
+		delete(h.watchers, w)
+		h.mu.Unlock()
+	}
+}
+
+// publish delivers raw line to the watchers and the subscribers.
+// The captured lines are parts of command responses, they are not delivered
+// to the subscribers unless they are log lines. The captured log lines are
+// server events received during the command, they are delivered unless they
+// are own lines: the command echo or the sentinel.
+func (h *logHub) publish(raw string, captured bool, own bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for w := range h.watchers {
+		w.fn(raw)
+	}
+
+	if len(h.subs) == 0 {
+		return
+	}
+
+	line := h.parser.Parse(raw)
+	if captured && (own || line.Level == "") {
+		return
+	}
+
+	for s := range h.subs {
+		s.send(line, h.policy)
+	}
+}
+
+// close closes all subscriptions.
+func (h *logHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+
+	for s := range h.subs {
+		delete(h.subs, s)
+		close(s.lines)
+	}
+}
@@ -0,0 +1,150 @@
+package telnet
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CommandMemory is the command which prints memory information.
+const CommandMemory = "mem"
+
+// memFieldRegexp matches "key: value" pairs of mem line like
+// "Time: 518.02m FPS: 35.10 Heap: 1234.5MB ... Ent: 40 (120) Items: 5".
+var memFieldRegexp = regexp.MustCompile(`(\w+): (\S+(?: \(\d+\))?)`)
+
+// Memory units used by the server. Megabytes are binary.
+const (
+	kilobyte = 1 << 10
+	megabyte = 1 << 20
+	gigabyte = 1 << 30
+)
+
+// MemStats is the server performance snapshot returned by mem command.
+type MemStats struct {
+	// Uptime is the time passed since the server start.
+	Uptime time.Duration
+	FPS    float64
+
+	// Heap, MaxHeap and RSS are in bytes.
+	Heap    uint64
+	MaxHeap uint64
+	RSS     uint64
+
+	Chunks           int
+	ChunkGameObjects int
+	ChunkObservers   int
+	Players          int
+	Zombies          int
+	Entities         int
+
+	// EntitiesTotal is the number in parentheses after entities.
+	EntitiesTotal int
+	Items         int
+
+	// Extra contains the fields unknown to the parser.
+	Extra map[string]string
+}
+
+// Memory executes mem command and returns parsed server performance snapshot.
+func (c *Conn) Memory() (MemStats, error) {
+	response, err := c.Execute(CommandMemory)
+	if err != nil {
+		return MemStats{}, err
+	}
+
+	return ParseMemStats(response)
+}
+
+// ParseMemStats parses mem command response. ErrUnexpectedResponse is
+// returned if the response has no memory information.
+func ParseMemStats(response string) (MemStats, error) {
+	for _, line := range strings.Split(response, "\n") {
+		if !strings.Contains(line, "FPS: ") || !strings.Contains(line, "Heap: ") {
+			continue
+		}
+
+		stats := MemStats{Extra: make(map[string]string)}
+
+		// Skip log line prefix, "Time" field is the first one.
+		if i := strings.Index(line, "Time: "); i != -1 {
+			line = line[i:]
+		}
+
+		for _, matches := range memFieldRegexp.FindAllStringSubmatch(line, -1) {
+			if err := stats.set(matches[1], matches[2]); err != nil {
+				return stats, fmt.Errorf("telnet: %w", err)
+			}
+		}
+
+		return stats, nil
+	}
+
+	return MemStats{}, ErrUnexpectedResponse
+}
+
+// set sets the field associated with key.
+func (s *MemStats) set(key string, value string) error {
+	var err error
+
+	switch key {
+	case "Time":
+		s.Uptime, err = time.ParseDuration(value)
+	case "FPS":
+		s.FPS, err = strconv.ParseFloat(value, 64)
+	case "Heap":
+		s.Heap, err = parseBytes(value)
+	case "Max":
+		s.MaxHeap, err = parseBytes(value)
+	case "RSS":
+		s.RSS, err = parseBytes(value)
+	case "Chunks":
+		s.Chunks, err = strconv.Atoi(value)
+	case "CGO":
+		s.ChunkGameObjects, err = strconv.Atoi(value)
+	case "CO":
+		s.ChunkObservers, err = strconv.Atoi(value)
+	case "Ply":
+		s.Players, err = strconv.Atoi(value)
+	case "Zom":
+		s.Zombies, err = strconv.Atoi(value)
+	case "Ent":
+		entities, total, _ := strings.Cut(value, " ")
+		if s.Entities, err = strconv.Atoi(entities); err == nil && total != "" {
+			s.EntitiesTotal, err = strconv.Atoi(strings.Trim(total, "()"))
+		}
+	case "Items":
+		s.Items, err = strconv.Atoi(value)
+	default:
+		s.Extra[key] = value
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// parseBytes parses memory size like "1234.5MB" into bytes.
+func parseBytes(value string) (uint64, error) {
+	multiplier := 1.0
+
+	switch {
+	case strings.HasSuffix(value, "GB"):
+		multiplier = gigabyte
+	case strings.HasSuffix(value, "MB"):
+		multiplier = megabyte
+	case strings.HasSuffix(value, "KB"):
+		multiplier = kilobyte
+	}
+
+	number, err := strconv.ParseFloat(strings.TrimRight(value, "GMKB"), 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(number * multiplier), nil
+}
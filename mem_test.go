@@ -0,0 +1,82 @@
+package telnet_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gorcon/telnet"
+	"github.com/gorcon/telnet/telnettest"
+)
+
+// memResponse is the example mem response.
+const memResponse = "2020-12-07T21:37:00 31123.521 INF Time: 518.50m FPS: 35.10 Heap: 1234.5MB Max: 1500.0MB " +
+	"Chunks: 420 CGO: 12 Ply: 3 Zom: 25 Ent: 40 (120) Items: 5 CO: 3 RSS: 3.5GB"
+
+func TestParseMemStats(t *testing.T) {
+	t.Run("mem", func(t *testing.T) {
+		want := telnet.MemStats{
+			Uptime:           518*time.Minute + 30*time.Second,
+			FPS:              35.10,
+			Heap:             1294467072,
+			MaxHeap:          1572864000,
+			RSS:              3758096384,
+			Chunks:           420,
+			ChunkGameObjects: 12,
+			ChunkObservers:   3,
+			Players:          3,
+			Zombies:          25,
+			Entities:         40,
+			EntitiesTotal:    120,
+			Items:            5,
+			Extra:            map[string]string{},
+		}
+
+		got, err := telnet.ParseMemStats("*** some header\r\n" + memResponse)
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("unexpected response", func(t *testing.T) {
+		_, err := telnet.ParseMemStats("*** ERROR: unknown command 'mem'")
+		if !errors.Is(err, telnet.ErrUnexpectedResponse) {
+			t.Errorf("got err %q, want %q", err, telnet.ErrUnexpectedResponse)
+		}
+	})
+
+	t.Run("invalid number", func(t *testing.T) {
+		if _, err := telnet.ParseMemStats("Time: 1.00m FPS: fast Heap: 1MB"); err == nil {
+			t.Error("got nil err, want error")
+		}
+	})
+}
+
+func TestConn_Memory(t *testing.T) {
+	server := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+		telnettest.SetAuthHandler(authHandler),
+		telnettest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	conn, err := telnet.Dial(server.Addr(), "password")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	stats, err := conn.Memory()
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if stats.Players != 3 || stats.Heap != 1294467072 {
+		t.Errorf("got %+v, want parsed mem response", stats)
+	}
+}
@@ -0,0 +1,175 @@
+package telnet
+
+import "bytes"
+
+// TELNET commands, see RFC 854.
+const (
+	SE   byte = 240 // End of subnegotiation parameters.
+	NOP  byte = 241 // No operation.
+	DM   byte = 242 // Data Mark, the data stream portion of a Synch.
+	BRK  byte = 243 // NVT character BRK.
+	IP   byte = 244 // Interrupt Process.
+	AO   byte = 245 // Abort output.
+	AYT  byte = 246 // Are You There.
+	EC   byte = 247 // Erase character.
+	EL   byte = 248 // Erase Line.
+	GA   byte = 249 // Go ahead.
+	SB   byte = 250 // Subnegotiation of the indicated option follows.
+	WILL byte = 251 // Indicates the desire to begin performing the indicated option.
+	WONT byte = 252 // Indicates the refusal to perform the indicated option.
+	DO   byte = 253 // Indicates the request that the other party perform the indicated option.
+	DONT byte = 254 // Indicates the demand that the other party stop performing the indicated option.
+	IAC  byte = 255 // Interpret as command.
+)
+
+// OptionHandler handles negotiation of a single TELNET option.
+type OptionHandler interface {
+	// Negotiate is called when the server sends WILL, WONT, DO or DONT for
+	// the option. It returns the command to answer with, or 0 to send
+	// nothing.
+	Negotiate(command byte) byte
+
+	// Subnegotiate is called with the data received between IAC SB <option>
+	// and IAC SE. It returns the subnegotiation data to send back, or nil
+	// to send nothing.
+	Subnegotiate(data []byte) []byte
+}
+
+// negotiator states.
+const (
+	stateData = iota
+	stateIAC
+	stateOption
+	stateSB
+	stateSBData
+	stateSBIAC
+)
+
+// negotiator strips TELNET command sequences from received data and builds
+// the answers to option negotiation.
+type negotiator struct {
+	handlers map[byte]OptionHandler
+	state    int
+	command  byte
+	option   byte
+	sub      []byte
+}
+
+// filter returns the data bytes of p and the answers which must be sent to
+// the server. Command sequences may be split between calls. If p has no
+// command sequences, data is p itself.
+func (n *negotiator) filter(p []byte) (data []byte, reply []byte) {
+	if n.state == stateData && bytes.IndexByte(p, IAC) == -1 {
+		// Plain data, nothing to filter.
+		return p, nil
+	}
+
+	data = make([]byte, 0, len(p))
+
+	for _, b := range p {
+		switch n.state {
+		case stateData:
+			if b == IAC {
+				n.state = stateIAC
+			} else {
+				data = append(data, b)
+			}
+		case stateIAC:
+			data = n.iac(b, data)
+		case stateOption:
+			n.state = stateData
+			reply = append(reply, n.negotiate(n.command, b)...)
+		case stateSB:
+			n.option = b
+			n.sub = n.sub[:0]
+			n.state = stateSBData
+		case stateSBData:
+			if b == IAC {
+				n.state = stateSBIAC
+			} else {
+				n.sub = append(n.sub, b)
+			}
+		case stateSBIAC:
+			switch b {
+			case SE:
+				n.state = stateData
+				reply = append(reply, n.subnegotiate(n.option, n.sub)...)
+			case IAC:
+				n.state = stateSBData
+				n.sub = append(n.sub, b)
+			default:
+				// Broken subnegotiation, b is the command following IAC.
+				data = n.iac(b, data)
+			}
+		}
+	}
+
+	return data, reply
+}
+
+// iac handles the command byte following IAC. The escaped 255 data byte is
+// appended to data.
+func (n *negotiator) iac(b byte, data []byte) []byte {
+	n.state = stateData
+
+	switch b {
+	case IAC:
+		data = append(data, b)
+	case WILL, WONT, DO, DONT:
+		n.command = b
+		n.state = stateOption
+	case SB:
+		n.state = stateSB
+	}
+
+	return data
+}
+
+// negotiate returns the answer to the command for the option. Options
+// without handler are refused: WILL is answered with DONT and DO is answered
+// with WONT.
+func (n *negotiator) negotiate(command byte, option byte) []byte {
+	var answer byte
+
+	if handler, ok := n.handlers[option]; ok {
+		answer = handler.Negotiate(command)
+	} else {
+		switch command {
+		case WILL:
+			answer = DONT
+		case DO:
+			answer = WONT
+		}
+	}
+
+	if answer == 0 {
+		return nil
+	}
+
+	return []byte{IAC, answer, option}
+}
+
+// subnegotiate returns the answer to the subnegotiation data for the option.
+func (n *negotiator) subnegotiate(option byte, data []byte) []byte {
+	handler, ok := n.handlers[option]
+	if !ok {
+		return nil
+	}
+
+	answer := handler.Subnegotiate(data)
+	if answer == nil {
+		return nil
+	}
+
+	reply := []byte{IAC, SB, option}
+
+	for _, b := range answer {
+		if b == IAC {
+			reply = append(reply, IAC)
+		}
+
+		reply = append(reply, b)
+	}
+
+	return append(reply, IAC, SE)
+}
@@ -1,19 +1,52 @@
 package telnet
 
-import "time"
+import (
+	"crypto/tls"
+	"log/slog"
+	"time"
+)
 
 // Settings contains option to Conn.
 type Settings struct {
-	dialTimeout   time.Duration
-	exitCommand   string
-	clearResponse bool
+	dialer              Dialer
+	dialTimeout         time.Duration
+	tlsConfig           *tls.Config
+	refusePlaintextAuth bool
+	trustedTransport    bool
+	exitCommand         string
+	clearResponse       bool
+	framing             Framing
+	quietPeriod         time.Duration
+	executeTimeout      time.Duration
+	optionHandlers      map[byte]OptionHandler
+	logBufferSize       int
+	logOverflow         OverflowPolicy
+	maxBufferSize       int
+	bufferOverflow      OverflowPolicy
+
+	reconnect         bool
+	reconnectBackoff  time.Duration
+	reconnectAttempts int
+	reconnectHandler  ReconnectHandler
+
+	logger  *slog.Logger
+	metrics Metrics
+	dialect Dialect
 }
 
 // DefaultSettings provides default deadline settings to Conn.
 var DefaultSettings = Settings{
-	dialTimeout:   DefaultDialTimeout,
-	exitCommand:   DefaultExitCommand,
-	clearResponse: false,
+	dialTimeout:    DefaultDialTimeout,
+	exitCommand:    DefaultExitCommand,
+	clearResponse:  false,
+	framing:        framingDialect,
+	quietPeriod:    DefaultQuietPeriod,
+	executeTimeout: DefaultExecuteTimeout,
+	logBufferSize:  DefaultLogBufferSize,
+	logOverflow:    DropNewest,
+	maxBufferSize:  DefaultMaxBufferSize,
+	bufferOverflow: FailOnOverflow,
+	dialect:        SevenDaysDialect{},
 }
 
 // Option allows to inject settings to Settings.
@@ -26,6 +59,44 @@ func SetDialTimeout(timeout time.Duration) Option {
 	}
 }
 
+// SetDialer injects Dialer used to open network connections, for example
+// through SOCKS5 proxy or SSH tunnel. Dial timeout is applied to the
+// dialer context.
+func SetDialer(dialer Dialer) Option {
+	return func(s *Settings) {
+		s.dialer = dialer
+	}
+}
+
+// SetTLSConfig injects TLS configuration. The connection is encrypted with
+// TLS before auth, for example to connect to the server behind stunnel.
+// If ServerName is empty, the host from the dialed address is used.
+func SetTLSConfig(config *tls.Config) Option {
+	return func(s *Settings) {
+		s.tlsConfig = config
+	}
+}
+
+// SetRefusePlaintextAuth injects the option to refuse sending the password
+// over unencrypted connection to the server which is not on the local host.
+// The dialed address is checked, not the address of the proxy. Connections
+// to other networks than TCP and UDP are refused too unless
+// SetTrustedTransport is set. ErrInsecureAuth is returned in this case.
+func SetRefusePlaintextAuth(refuse bool) Option {
+	return func(s *Settings) {
+		s.refusePlaintextAuth = refuse
+	}
+}
+
+// SetTrustedTransport injects the option to consider the transport secure,
+// for example SSH tunnel opened by Dialer or Unix socket passed to NewConn.
+// The password is sent over it even with SetRefusePlaintextAuth.
+func SetTrustedTransport(trusted bool) Option {
+	return func(s *Settings) {
+		s.trustedTransport = trusted
+	}
+}
+
 // SetExitCommand injects telnet exit command.
 func SetExitCommand(command string) Option {
 	return func(s *Settings) {
@@ -39,3 +110,113 @@ func SetClearResponse(clear bool) Option {
 		s.clearResponse = clear
 	}
 }
+
+// SetFraming injects the way the end of a command response is detected.
+// The framing of the dialect is used by default, see Dialect.Framing.
+// Use FramingSleep to get the behaviour of the previous versions.
+func SetFraming(framing Framing) Option {
+	return func(s *Settings) {
+		s.framing = framing
+	}
+}
+
+// SetQuietPeriod injects period of silence after which the response
+// is considered complete.
+func SetQuietPeriod(period time.Duration) Option {
+	return func(s *Settings) {
+		s.quietPeriod = period
+	}
+}
+
+// SetExecuteTimeout injects maximum time to wait for the response
+// to be complete. When it expires, ErrResponseTimeout is returned along
+// with the partial response. For ExecuteStream and ExecuteLines it limits
+// the time between the received lines instead.
+func SetExecuteTimeout(timeout time.Duration) Option {
+	return func(s *Settings) {
+		s.executeTimeout = timeout
+	}
+}
+
+// SetOptionHandler injects handler for TELNET option negotiation. Options
+// without handler are refused.
+func SetOptionHandler(option byte, handler OptionHandler) Option {
+	return func(s *Settings) {
+		handlers := make(map[byte]OptionHandler, len(s.optionHandlers)+1)
+		for o, h := range s.optionHandlers {
+			handlers[o] = h
+		}
+
+		handlers[option] = handler
+		s.optionHandlers = handlers
+	}
+}
+
+// SetLogBuffer injects capacity of Subscription channel and the policy
+// applied when a subscriber doesn't keep up with the server log.
+func SetLogBuffer(size int, policy OverflowPolicy) Option {
+	return func(s *Settings) {
+		s.logBufferSize = size
+		s.logOverflow = policy
+	}
+}
+
+// SetMaxBufferSize injects the maximum size of a command response and of
+// an unfinished server log line in bytes and the policy applied when
+// a response exceeds it. Zero size means no limit. Discarded bytes are
+// counted in Stats.DroppedBytes.
+func SetMaxBufferSize(size int, policy OverflowPolicy) Option {
+	return func(s *Settings) {
+		s.maxBufferSize = size
+		s.bufferOverflow = policy
+	}
+}
+
+// SetReconnect enables restoring of the connection closed by the server.
+// The connection is redialed and authorized with the same password,
+// subscriptions stay alive. The delay before the attempt starts with
+// backoff and doubles with each failed attempt. maxAttempts less than
+// 1 means unlimited attempts.
+func SetReconnect(backoff time.Duration, maxAttempts int) Option {
+	return func(s *Settings) {
+		s.reconnect = true
+		s.reconnectBackoff = backoff
+		s.reconnectAttempts = maxAttempts
+	}
+}
+
+// SetReconnectHandler injects handler of reconnection events.
+func SetReconnectHandler(handler ReconnectHandler) Option {
+	return func(s *Settings) {
+		s.reconnectHandler = handler
+	}
+}
+
+// SetLogger injects logger for dial, auth, commands and connection close
+// records. Commands are logged with debug level. The password is always
+// redacted.
+func SetLogger(logger *slog.Logger) Option {
+	return func(s *Settings) {
+		s.logger = logger
+	}
+}
+
+// SetMetrics injects metrics receiving executed commands, sent and received
+// bytes and reconnections.
+func SetMetrics(metrics Metrics) Option {
+	return func(s *Settings) {
+		s.metrics = metrics
+	}
+}
+
+// SetDialect injects the dialect of the server console. SevenDaysDialect
+// is used by default or if dialect is nil.
+func SetDialect(dialect Dialect) Option {
+	return func(s *Settings) {
+		if dialect == nil {
+			dialect = SevenDaysDialect{}
+		}
+
+		s.dialect = dialect
+	}
+}
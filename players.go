@@ -0,0 +1,177 @@
+package telnet
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CommandListPlayers is the command which lists online players.
+const CommandListPlayers = "listplayers"
+
+// playerLineRegexp matches the beginning of listplayers line like
+// "1. id=171, Name, pos=(...), ...". The name may contain commas,
+// so it ends at ", pos=(".
+var playerLineRegexp = regexp.MustCompile(`^\d+\. id=(-?\d+), (.*?), (pos=\(.*)$`)
+
+// Vector3 is a position or rotation in the game world.
+type Vector3 struct {
+	X float64
+	Y float64
+	Z float64
+}
+
+// Player is an online player returned by listplayers command.
+type Player struct {
+	ID       int
+	Name     string
+	Position Vector3
+	Rotation Vector3
+	Remote   bool
+	Health   int
+	Deaths   int
+	Zombies  int
+	Players  int
+	Score    int
+	Level    int
+
+	// PlatformID is the platform user ID, such as "Steam_76561198000000000".
+	// Older versions send it as steamid.
+	PlatformID string
+
+	// CrossID is the cross-platform user ID, such as "EOS_0002...".
+	CrossID string
+
+	IP   string
+	Ping int
+
+	// Extra contains the fields unknown to the parser.
+	Extra map[string]string
+}
+
+// ListPlayers executes listplayers command and returns online players.
+func (c *Conn) ListPlayers() ([]Player, error) {
+	response, err := c.Execute(CommandListPlayers)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseListPlayers(response)
+}
+
+// ParseListPlayers parses listplayers command response. The lines which
+// don't describe a player are skipped.
+func ParseListPlayers(response string) ([]Player, error) {
+	players := make([]Player, 0)
+
+	for _, line := range strings.Split(response, "\n") {
+		matches := playerLineRegexp.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil {
+			continue
+		}
+
+		player := Player{Name: matches[2], Extra: make(map[string]string)}
+		player.ID, _ = strconv.Atoi(matches[1])
+
+		for _, field := range splitFields(matches[3]) {
+			key, value, _ := strings.Cut(field, "=")
+			if err := player.set(key, value); err != nil {
+				return players, fmt.Errorf("telnet: player %d: %w", player.ID, err)
+			}
+		}
+
+		players = append(players, player)
+	}
+
+	return players, nil
+}
+
+// set sets the field associated with key.
+func (p *Player) set(key string, value string) error {
+	var err error
+
+	switch key {
+	case "pos":
+		p.Position, err = parseVector3(value)
+	case "rot":
+		p.Rotation, err = parseVector3(value)
+	case "remote":
+		p.Remote, err = strconv.ParseBool(value)
+	case "health":
+		p.Health, err = strconv.Atoi(value)
+	case "deaths":
+		p.Deaths, err = strconv.Atoi(value)
+	case "zombies":
+		p.Zombies, err = strconv.Atoi(value)
+	case "players":
+		p.Players, err = strconv.Atoi(value)
+	case "score":
+		p.Score, err = strconv.Atoi(value)
+	case "level":
+		p.Level, err = strconv.Atoi(value)
+	case "pltfmid", "steamid":
+		p.PlatformID = value
+	case "crossid":
+		p.CrossID = value
+	case "ip":
+		p.IP = value
+	case "ping":
+		p.Ping, err = strconv.Atoi(value)
+	default:
+		p.Extra[key] = value
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// splitFields splits "key=value, key=(x, y, z)" into fields. Commas inside
+// parentheses don't split fields.
+func splitFields(s string) []string {
+	var (
+		fields []string
+		depth  int
+		start  int
+	)
+
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				fields = append(fields, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+
+	return append(fields, strings.TrimSpace(s[start:]))
+}
+
+// parseVector3 parses vector like "(-1234.5, 61.1, 567.8)".
+func parseVector3(s string) (Vector3, error) {
+	var (
+		coordinates [3]float64
+		err         error
+	)
+
+	parts := strings.Split(strings.Trim(s, "()"), ",")
+	if len(parts) != len(coordinates) {
+		return Vector3{}, fmt.Errorf("invalid vector %q", s)
+	}
+
+	for i, part := range parts {
+		if coordinates[i], err = strconv.ParseFloat(strings.TrimSpace(part), 64); err != nil {
+			return Vector3{}, err
+		}
+	}
+
+	return Vector3{X: coordinates[0], Y: coordinates[1], Z: coordinates[2]}, nil
+}
@@ -0,0 +1,106 @@
+package telnet_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorcon/telnet"
+	"github.com/gorcon/telnet/telnettest"
+)
+
+// listPlayersResponse is the example listplayers response.
+const listPlayersResponse = "0. id=171, Player, One, pos=(-1234.5, 61.1, 567.8), rot=(-30.9, 1234.5, 0.0), remote=True, " +
+	"health=100, deaths=2, zombies=35, players=1, score=29, level=12, pltfmid=Steam_76561198000000000, " +
+	"crossid=EOS_0002abcdef, ip=192.168.0.10, ping=30, newfield=42\r\n" +
+	"1. id=172, Bob, pos=(1.0, 2.0, 3.0), rot=(0.0, 0.0, 0.0), remote=False, health=50, deaths=0, zombies=0, " +
+	"players=0, score=0, level=1, steamid=76561198000000001, ip=10.0.0.1, ping=5\r\n" +
+	"Total of 2 in the game"
+
+func TestParseListPlayers(t *testing.T) {
+	t.Run("players", func(t *testing.T) {
+		want := []telnet.Player{
+			{
+				ID:         171,
+				Name:       "Player, One",
+				Position:   telnet.Vector3{X: -1234.5, Y: 61.1, Z: 567.8},
+				Rotation:   telnet.Vector3{X: -30.9, Y: 1234.5, Z: 0},
+				Remote:     true,
+				Health:     100,
+				Deaths:     2,
+				Zombies:    35,
+				Players:    1,
+				Score:      29,
+				Level:      12,
+				PlatformID: "Steam_76561198000000000",
+				CrossID:    "EOS_0002abcdef",
+				IP:         "192.168.0.10",
+				Ping:       30,
+				Extra:      map[string]string{"newfield": "42"},
+			},
+			{
+				ID:         172,
+				Name:       "Bob",
+				Position:   telnet.Vector3{X: 1, Y: 2, Z: 3},
+				Health:     50,
+				Level:      1,
+				PlatformID: "76561198000000001",
+				IP:         "10.0.0.1",
+				Ping:       5,
+				Extra:      map[string]string{},
+			},
+		}
+
+		got, err := telnet.ParseListPlayers(listPlayersResponse)
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("no players", func(t *testing.T) {
+		got, err := telnet.ParseListPlayers("Total of 0 in the game")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if len(got) != 0 {
+			t.Errorf("got %d players, want %d", len(got), 0)
+		}
+	})
+
+	t.Run("invalid position", func(t *testing.T) {
+		response := strings.Replace(listPlayersResponse, "pos=(1.0, 2.0, 3.0)", "pos=(1.0, 2.0)", 1)
+
+		if _, err := telnet.ParseListPlayers(response); err == nil {
+			t.Error("got nil err, want error")
+		}
+	})
+}
+
+func TestConn_ListPlayers(t *testing.T) {
+	server := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+		telnettest.SetAuthHandler(authHandler),
+		telnettest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	conn, err := telnet.Dial(server.Addr(), "password")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	players, err := conn.ListPlayers()
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if len(players) != 2 || players[1].Name != "Bob" {
+		t.Errorf("got %+v, want 2 players", players)
+	}
+}
@@ -0,0 +1,271 @@
+package telnet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// DefaultPoolMaxIdle provides default maximum number of idle connections
+// in Pool.
+const DefaultPoolMaxIdle = 2
+
+// PoolConfig contains configuration for Pool.
+type PoolConfig struct {
+	// MaxOpen is the maximum number of open connections. Zero means
+	// unlimited.
+	MaxOpen int
+
+	// MaxIdle is the maximum number of idle connections. Zero means
+	// DefaultPoolMaxIdle.
+	MaxIdle int
+
+	// IdleTimeout is the maximum time a connection may be idle before it is
+	// closed. Idle connections are checked in background, the broken ones
+	// are closed too. Zero means connections are not closed due to idle
+	// time.
+	IdleTimeout time.Duration
+}
+
+// Pool is a set of authorized TELNET connections to the same server.
+// It is safe for concurrent use by multiple goroutines.
+type Pool struct {
+	address  string
+	password string
+	options  []Option
+	config   PoolConfig
+	sem      chan struct{}
+
+	mu     sync.Mutex
+	idle   []idleConn
+	inUse  int
+	closed bool
+	quit   chan struct{}
+}
+
+// idleConn is the connection waiting in Pool.
+type idleConn struct {
+	conn     *Conn
+	returned time.Time
+}
+
+// PoolStats contains Pool statistics.
+type PoolStats struct {
+	// InUse is the number of connections executing commands.
+	InUse int
+
+	// Idle is the number of idle connections.
+	Idle int
+}
+
+// NewPool creates a new Pool. Connections are opened lazily with Dial
+// options. The caller should call Close when finished.
+func NewPool(address string, password string, config PoolConfig, options ...Option) *Pool {
+	if config.MaxIdle == 0 {
+		config.MaxIdle = DefaultPoolMaxIdle
+	}
+
+	pool := Pool{address: address, password: password, options: options, config: config}
+	if config.MaxOpen > 0 {
+		pool.sem = make(chan struct{}, config.MaxOpen)
+	}
+
+	if config.IdleTimeout > 0 {
+		pool.quit = make(chan struct{})
+
+		go pool.reap(pool.quit)
+	}
+
+	return &pool
+}
+
+// Execute sends command string to execute to the remote TELNET server
+// over a connection from the pool.
+func (p *Pool) Execute(command string) (string, error) {
+	return p.ExecuteContext(context.Background(), command)
+}
+
+// ExecuteContext sends command string to execute to the remote TELNET
+// server over a connection from the pool using the provided context.
+// It blocks while MaxOpen connections are in use.
+func (p *Pool) ExecuteContext(ctx context.Context, command string) (string, error) {
+	conn, err := p.get(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := conn.ExecuteContext(ctx, command)
+
+	// The connection may be out of sync after the failure, only the console
+	// errors leave it usable.
+	var commandErr *CommandError
+	p.put(conn, err == nil || errors.As(err, &commandErr))
+
+	return response, err
+}
+
+// Stats returns the pool statistics.
+func (p *Pool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return PoolStats{InUse: p.inUse, Idle: len(p.idle)}
+}
+
+// Close closes idle connections. Connections in use are closed when
+// returned to the pool.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+
+	if !p.closed && p.quit != nil {
+		close(p.quit)
+	}
+
+	p.closed = true
+	p.mu.Unlock()
+
+	var err error
+
+	for _, ic := range idle {
+		if err2 := ic.conn.Close(); err2 != nil && err == nil {
+			err = err2
+		}
+	}
+
+	return err
+}
+
+// get takes an idle healthy connection or dials a new one.
+func (p *Pool) get(ctx context.Context) (*Conn, error) {
+	if p.sem != nil {
+		select {
+		case p.sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("telnet: %w", ctx.Err())
+		}
+	}
+
+	conn, err := p.take()
+	if conn == nil && err == nil {
+		conn, err = DialContext(ctx, p.address, p.password, p.options...)
+	}
+
+	if err != nil {
+		p.release()
+
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.inUse++
+	p.mu.Unlock()
+
+	return conn, nil
+}
+
+// take returns an idle healthy connection or nil. Broken and expired
+// connections are closed.
+func (p *Pool) take() (*Conn, error) {
+	p.evict()
+
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+
+			return nil, fmt.Errorf("telnet: %w", net.ErrClosed)
+		}
+
+		if len(p.idle) == 0 {
+			p.mu.Unlock()
+
+			return nil, nil
+		}
+
+		ic := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		p.mu.Unlock()
+
+		if !p.expired(ic) && ic.conn.alive() {
+			return ic.conn, nil
+		}
+
+		_ = ic.conn.Close()
+	}
+}
+
+// put returns conn to the pool if reuse is true. It closes conn when it is
+// broken or the pool has enough idle connections.
+func (p *Pool) put(conn *Conn, reuse bool) {
+	defer p.release()
+
+	p.mu.Lock()
+	p.inUse--
+
+	if reuse && !p.closed && conn.alive() && len(p.idle) < p.config.MaxIdle {
+		p.idle = append(p.idle, idleConn{conn: conn, returned: time.Now()})
+		p.mu.Unlock()
+
+		return
+	}
+	p.mu.Unlock()
+
+	_ = conn.Close()
+}
+
+// evict closes all idle connections which are expired or broken.
+func (p *Pool) evict() {
+	var stale []*Conn
+
+	p.mu.Lock()
+	idle := p.idle[:0]
+
+	for _, ic := range p.idle {
+		if p.expired(ic) || !ic.conn.alive() {
+			stale = append(stale, ic.conn)
+		} else {
+			idle = append(idle, ic)
+		}
+	}
+
+	clear(p.idle[len(idle):])
+	p.idle = idle
+	p.mu.Unlock()
+
+	for _, conn := range stale {
+		_ = conn.Close()
+	}
+}
+
+// reap evicts idle connections every half of IdleTimeout until quit is
+// closed.
+func (p *Pool) reap(quit chan struct{}) {
+	ticker := time.NewTicker(p.config.IdleTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-quit:
+			return
+		case <-ticker.C:
+			p.evict()
+		}
+	}
+}
+
+// expired reports whether ic is idle longer than IdleTimeout.
+func (p *Pool) expired(ic idleConn) bool {
+	return p.config.IdleTimeout > 0 && time.Since(ic.returned) > p.config.IdleTimeout
+}
+
+// release frees the place of the connection in use.
+func (p *Pool) release() {
+	if p.sem != nil {
+		<-p.sem
+	}
+}
@@ -0,0 +1,170 @@
+package telnet_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorcon/telnet"
+	"github.com/gorcon/telnet/telnettest"
+)
+
+func TestPool(t *testing.T) {
+	var dials atomic.Int32
+
+	server := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+		telnettest.SetAuthHandler(func(c *telnettest.Context) {
+			if c.Request() == c.Server().Settings.Password {
+				dials.Add(1)
+			}
+
+			authHandler(c)
+		}),
+		telnettest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	t.Run("max open", func(t *testing.T) {
+		dials.Store(0)
+
+		pool := telnet.NewPool(server.Addr(), "password", telnet.PoolConfig{MaxOpen: 2},
+			telnet.SetClearResponse(true), telnet.SetQuietPeriod(10*time.Millisecond))
+		defer pool.Close()
+
+		var wg sync.WaitGroup
+
+		for w := 0; w < 6; w++ {
+			wg.Add(1)
+
+			go func(w int) {
+				defer wg.Done()
+
+				for i := 0; i < 3; i++ {
+					want := fmt.Sprintf("worker %d command %d", w, i)
+
+					result, err := pool.Execute("echo " + want)
+					if err != nil {
+						t.Errorf("got err %q, want %v", err, nil)
+					} else if result != want {
+						t.Errorf("got result %q, want %q", result, want)
+					}
+				}
+			}(w)
+		}
+
+		wg.Wait()
+
+		if got := dials.Load(); got > 2 {
+			t.Errorf("got %d dials, want at most %d", got, 2)
+		}
+
+		if stats := pool.Stats(); stats.InUse != 0 || stats.Idle != int(dials.Load()) {
+			t.Errorf("got stats %+v, want 0 in use and %d idle", stats, dials.Load())
+		}
+	})
+
+	t.Run("evict broken", func(t *testing.T) {
+		dials.Store(0)
+
+		pool := telnet.NewPool(server.Addr(), "password", telnet.PoolConfig{}, telnet.SetClearResponse(true))
+		defer pool.Close()
+
+		if _, err := pool.Execute("help"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		server.CloseClientConnections()
+		time.Sleep(50 * time.Millisecond)
+
+		result, err := pool.Execute("help")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "lorem ipsum dolor sit amet" {
+			t.Fatalf("got result %q, want %q", result, "lorem ipsum dolor sit amet")
+		}
+
+		if got := dials.Load(); got != 2 {
+			t.Errorf("got %d dials, want %d", got, 2)
+		}
+	})
+
+	t.Run("idle timeout", func(t *testing.T) {
+		dials.Store(0)
+
+		pool := telnet.NewPool(server.Addr(), "password", telnet.PoolConfig{IdleTimeout: 10 * time.Millisecond})
+		defer pool.Close()
+
+		if _, err := pool.Execute("help"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		time.Sleep(50 * time.Millisecond)
+
+		if _, err := pool.Execute("help"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if got := dials.Load(); got != 2 {
+			t.Errorf("got %d dials, want %d", got, 2)
+		}
+	})
+
+	t.Run("reap idle", func(t *testing.T) {
+		pool := telnet.NewPool(server.Addr(), "password", telnet.PoolConfig{IdleTimeout: 20 * time.Millisecond})
+		defer pool.Close()
+
+		if _, err := pool.Execute("help"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		time.Sleep(100 * time.Millisecond)
+
+		if stats := pool.Stats(); stats.Idle != 0 {
+			t.Errorf("got stats %+v, want expired connection closed", stats)
+		}
+	})
+
+	t.Run("close after canceled command", func(t *testing.T) {
+		pool := telnet.NewPool(server.Addr(), "password", telnet.PoolConfig{})
+		defer pool.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		if _, err := pool.ExecuteContext(ctx, "slowish"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("got err %q, want %q", err, context.DeadlineExceeded)
+		}
+
+		if stats := pool.Stats(); stats.InUse != 0 || stats.Idle != 0 {
+			t.Errorf("got stats %+v, want the connection closed", stats)
+		}
+
+		if _, err := pool.Execute("random"); !errors.Is(err, telnet.ErrUnknownCommand) {
+			t.Fatalf("got err %q, want %q", err, telnet.ErrUnknownCommand)
+		}
+
+		if stats := pool.Stats(); stats.Idle != 1 {
+			t.Errorf("got stats %+v, want the connection kept after console error", stats)
+		}
+	})
+
+	t.Run("auth failed", func(t *testing.T) {
+		pool := telnet.NewPool(server.Addr(), "wrong", telnet.PoolConfig{MaxOpen: 1})
+		defer pool.Close()
+
+		if _, err := pool.Execute("help"); err == nil {
+			t.Fatal("got nil err, want error")
+		}
+
+		if stats := pool.Stats(); stats.InUse != 0 || stats.Idle != 0 {
+			t.Errorf("got stats %+v, want empty pool", stats)
+		}
+	})
+}
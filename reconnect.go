@@ -0,0 +1,256 @@
+package telnet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"time"
+)
+
+// MaxReconnectBackoff is the maximum delay between reconnection attempts.
+const MaxReconnectBackoff = time.Minute
+
+// ReconnectStatus is the result of a reconnection attempt.
+type ReconnectStatus int
+
+const (
+	// ReconnectFailed is reported when a reconnection attempt failed.
+	ReconnectFailed ReconnectStatus = iota
+
+	// Reconnected is reported when the connection was restored.
+	Reconnected
+
+	// ReconnectGaveUp is reported when all reconnection attempts failed.
+	// The connection is not restored after that and commands return
+	// ErrConnectionLost.
+	ReconnectGaveUp
+)
+
+// ReconnectEvent describes the reconnection progress.
+type ReconnectEvent struct {
+	Status  ReconnectStatus
+	Attempt int
+
+	// Err is the cause of the failure. It is nil for Reconnected.
+	Err error
+}
+
+// ReconnectHandler is called on every reconnection event. It is called from
+// the reconnection goroutine and must not block or call Conn.Close.
+type ReconnectHandler func(event ReconnectEvent)
+
+// broken handles the failure of the connection which reader closed done
+// channel. It starts reconnection if it is enabled and returns true if
+// the connection is going to be restored.
+func (c *Conn) broken(done chan struct{}, cause error) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed || !c.ready || !c.settings.reconnect || c.lost != nil {
+		return false
+	}
+
+	if c.readDone == done && c.reconnecting == nil {
+		c.reconnecting = make(chan struct{})
+
+		c.log(slog.LevelWarn, "telnet: connection lost", "error", cause)
+
+		c.wg.Add(1)
+
+		go c.reconnect(cause)
+	}
+
+	return true
+}
+
+// acquire takes the place in the command queue when the connection is
+// established. It returns the channel which is closed when the reader
+// of the connection stops. If the connection is lost and is not going
+// to be restored, the reason is returned, see Err.
+func (c *Conn) acquire(ctx context.Context) (chan struct{}, error) {
+	for {
+		c.mu.Lock()
+		reconnecting, lost := c.reconnecting, c.lost
+		stopped := c.err != nil && !c.closed
+		c.mu.Unlock()
+
+		if lost != nil {
+			return nil, fmt.Errorf("telnet: %w: %w", ErrConnectionLost, lost)
+		}
+
+		if stopped && reconnecting == nil {
+			// The commands written to the dead connection would fail with
+			// the raw network error.
+			return nil, c.Err()
+		}
+
+		if reconnecting != nil {
+			select {
+			case <-reconnecting:
+			case <-ctx.Done():
+				return nil, fmt.Errorf("telnet: %w", ctx.Err())
+			}
+		}
+
+		if err := c.lock(ctx); err != nil {
+			return nil, err
+		}
+
+		c.mu.Lock()
+		done, reconnecting := c.readDone, c.reconnecting
+		c.mu.Unlock()
+
+		if reconnecting == nil {
+			return done, nil
+		}
+
+		// Reconnection started while waiting for the queue.
+		c.unlock()
+	}
+}
+
+// reconnect restores the broken connection. It takes the command queue, so
+// no commands are sent until the new connection is authorized.
+func (c *Conn) reconnect(cause error) {
+	defer c.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-c.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	c.mu.Lock()
+	old, oldDone := c.conn, c.readDone
+	c.mu.Unlock()
+
+	_ = old.Close()
+	<-oldDone
+
+	err := c.lock(ctx)
+	if err == nil {
+		defer c.unlock()
+
+		err = cause
+
+		for attempt := 1; c.settings.reconnectAttempts < 1 || attempt <= c.settings.reconnectAttempts; attempt++ {
+			if err = sleep(ctx, backoff(c.settings.reconnectBackoff, attempt)); err != nil {
+				break
+			}
+
+			if err = c.redial(ctx); err == nil {
+				c.finishReconnect(nil)
+				c.notify(ReconnectEvent{Status: Reconnected, Attempt: attempt})
+
+				return
+			}
+
+			c.notify(ReconnectEvent{Status: ReconnectFailed, Attempt: attempt, Err: err})
+		}
+	}
+
+	c.finishReconnect(err)
+	c.stop(err)
+	c.notify(ReconnectEvent{Status: ReconnectGaveUp, Err: err})
+}
+
+// redial opens and authorizes a new connection.
+func (c *Conn) redial(ctx context.Context) error {
+	conn, err := dial(ctx, c.network, c.address, c.settings)
+	if err != nil {
+		return err
+	}
+
+	c.buffer.Capture()
+	defer c.buffer.Release()
+
+	if err := c.attach(conn); err != nil {
+		return err
+	}
+
+	if err := c.auth(ctx, c.password); err != nil {
+		c.mu.Lock()
+		done := c.readDone
+		c.mu.Unlock()
+
+		_ = conn.Close()
+		<-done
+
+		return err
+	}
+
+	return nil
+}
+
+// finishReconnect wakes up the commands waiting for reconnection. If err is
+// not nil, the connection is considered lost.
+func (c *Conn) finishReconnect(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lost = err
+	close(c.reconnecting)
+	c.reconnecting = nil
+}
+
+// notify calls ReconnectHandler if it is set.
+func (c *Conn) notify(event ReconnectEvent) {
+	switch event.Status {
+	case ReconnectFailed:
+		c.log(slog.LevelWarn, "telnet: reconnect failed", "attempt", event.Attempt, "error", event.Err)
+	case Reconnected:
+		c.reconnected()
+		c.log(slog.LevelInfo, "telnet: reconnected", "attempt", event.Attempt)
+	case ReconnectGaveUp:
+		c.log(slog.LevelError, "telnet: reconnect gave up", "error", event.Err)
+	}
+
+	if c.settings.reconnectHandler != nil {
+		c.settings.reconnectHandler(event)
+	}
+}
+
+// backoff returns delay before the reconnection attempt. The delay doubles
+// with each attempt up to MaxReconnectBackoff.
+func backoff(delay time.Duration, attempt int) time.Duration {
+	for i := 1; i < attempt && delay < MaxReconnectBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > MaxReconnectBackoff {
+		delay = MaxReconnectBackoff
+	}
+
+	return delay
+}
+
+// sleep pauses for delay or until ctx is done.
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("telnet: %w", ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
+
+// isWriteError reports whether err is returned by failed network write.
+func isWriteError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return opErr.Op == "write"
+	}
+
+	return errors.Is(err, io.ErrClosedPipe)
+}
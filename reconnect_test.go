@@ -0,0 +1,146 @@
+package telnet_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gorcon/telnet"
+	"github.com/gorcon/telnet/telnettest"
+)
+
+// waitEvent returns the first event with status or fails the test.
+func waitEvent(t *testing.T, events <-chan telnet.ReconnectEvent, status telnet.ReconnectStatus) telnet.ReconnectEvent {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case event := <-events:
+			if event.Status == status {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("event %d is not received", status)
+		}
+	}
+}
+
+func TestConn_Reconnect(t *testing.T) {
+	t.Run("reconnected", func(t *testing.T) {
+		server := telnettest.NewServer(
+			telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+			telnettest.SetAuthHandler(authHandler),
+			telnettest.SetCommandHandler(commandHandler),
+		)
+		defer server.Close()
+
+		events := make(chan telnet.ReconnectEvent, 10)
+
+		conn, err := telnet.Dial(server.Addr(), "password",
+			telnet.SetClearResponse(true),
+			telnet.SetFraming(telnet.FramingQuiet),
+			telnet.SetReconnect(10*time.Millisecond, 3),
+			telnet.SetReconnectHandler(func(event telnet.ReconnectEvent) { events <- event }),
+		)
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		sub := conn.Subscribe()
+		defer sub.Close()
+
+		server.CloseClientConnections()
+		waitEvent(t, events, telnet.Reconnected)
+
+		if got := conn.Stats().Reconnects; got != 1 {
+			t.Fatalf("got reconnects %d, want %d", got, 1)
+		}
+
+		result, err := conn.Execute("help")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "lorem ipsum dolor sit amet" {
+			t.Fatalf("got result %q, want %q", result, "lorem ipsum dolor sit amet")
+		}
+
+		if conn.Status() != telnettest.AuthSuccessWelcomeMessage {
+			t.Fatalf("got status %q, want %q", conn.Status(), telnettest.AuthSuccessWelcomeMessage)
+		}
+
+		if _, err := conn.Execute("chat"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		select {
+		case <-sub.Lines():
+		case <-time.After(time.Second):
+			t.Fatal("subscription is not alive after reconnect")
+		}
+	})
+
+	t.Run("gave up", func(t *testing.T) {
+		server := telnettest.NewServer(
+			telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+			telnettest.SetAuthHandler(authHandler),
+			telnettest.SetCommandHandler(commandHandler),
+		)
+
+		events := make(chan telnet.ReconnectEvent, 10)
+
+		conn, err := telnet.Dial(server.Addr(), "password",
+			telnet.SetReconnect(10*time.Millisecond, 2),
+			telnet.SetReconnectHandler(func(event telnet.ReconnectEvent) { events <- event }),
+		)
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		server.Close()
+
+		if event := waitEvent(t, events, telnet.ReconnectGaveUp); event.Err == nil {
+			t.Error("got nil event err, want error")
+		}
+
+		if _, err := conn.Execute("help"); !errors.Is(err, telnet.ErrConnectionLost) {
+			t.Errorf("got err %q, want %q", err, telnet.ErrConnectionLost)
+		}
+
+		select {
+		case <-conn.Done():
+		default:
+			t.Error("got alive connection, want done after giving up")
+		}
+
+		if err := conn.Err(); !errors.Is(err, telnet.ErrConnectionLost) {
+			t.Errorf("got err %q, want %q", err, telnet.ErrConnectionLost)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		server := telnettest.NewServer(
+			telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+			telnettest.SetAuthHandler(authHandler),
+			telnettest.SetCommandHandler(commandHandler),
+		)
+		defer server.Close()
+
+		conn, err := telnet.Dial(server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		server.CloseClientConnections()
+		time.Sleep(50 * time.Millisecond)
+
+		if _, err := conn.Execute("help"); err == nil {
+			t.Error("got nil err, want error")
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package telnet
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ExecuteResult is the response to a command split into the command output
+// and the server log lines received while the command was executed.
+type ExecuteResult struct {
+	Command string
+
+	// Output is the command output lines separated by "\n". It doesn't
+	// contain log lines, so the commands printing their result as log
+	// lines, such as "mem", have it in Logs.
+	Output string
+
+	// Logs are the log lines received during the command including
+	// the command echo line. The lines received before the echo, such
+	// as the leftovers of the previous command, are here too.
+	Logs []LogLine
+
+	// Raw is the response as Execute returns it without SetClearResponse:
+	// without TELNET command sequences, NUL bytes and the sentinel answer,
+	// with surrounding white space trimmed.
+	Raw []byte
+
+	// Sent is the time the command was sent at.
+	Sent time.Time
+
+	// Duration is the time passed until the response was complete.
+	Duration time.Duration
+}
+
+// ExecuteResult sends command string to execute to the remote TELNET server
+// and splits the response into the command output and the log lines.
+// Unlike SetClearResponse, it doesn't depend on the local address. If
+// the server reports a console error, *CommandError is returned along
+// with the result.
+func (c *Conn) ExecuteResult(ctx context.Context, command string) (ExecuteResult, error) {
+	sent := time.Now()
+
+	response, err := c.executeCommand(ctx, command, nil)
+
+	var commandErr *CommandError
+	if err != nil && !errors.As(err, &commandErr) {
+		return ExecuteResult{}, err
+	}
+
+	result := ParseExecuteResult(c.settings.dialect, command, response)
+	result.Sent = sent
+	result.Duration = time.Since(sent)
+
+	return result, err
+}
+
+// ParseExecuteResult splits the response to command into the command output
+// and the log lines. The output starts after the command echo recognised
+// by dialect. If there is no echo, the whole response is split. If dialect
+// is nil, SevenDaysDialect is used.
+func ParseExecuteResult(dialect Dialect, command string, response string) ExecuteResult {
+	if dialect == nil {
+		dialect = SevenDaysDialect{}
+	}
+
+	result := ExecuteResult{Command: command, Raw: []byte(response)}
+
+	lines := strings.Split(response, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], "\r")
+	}
+
+	start := 0
+
+	for i, raw := range lines {
+		if dialect.IsEcho(raw, command) {
+			start = i
+
+			break
+		}
+	}
+
+	var (
+		parser LogParser
+		output []string
+	)
+
+	for i, raw := range lines {
+		line := parser.Parse(raw)
+
+		// The lines before the echo are never the command output.
+		if i < start {
+			if raw != "" {
+				result.Logs = append(result.Logs, line)
+			}
+
+			continue
+		}
+
+		// Only indented lines, such as stack trace, continue an exception
+		// in the response. The rest is the command output.
+		if line.Continuation && !strings.HasPrefix(raw, " ") && !strings.HasPrefix(raw, "\t") {
+			parser = LogParser{}
+			line.Level = ""
+		}
+
+		if line.Level != "" {
+			result.Logs = append(result.Logs, line)
+
+			continue
+		}
+
+		output = append(output, raw)
+	}
+
+	result.Output = strings.TrimSpace(strings.Join(output, "\n"))
+
+	return result
+}
@@ -0,0 +1,104 @@
+package telnet_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/gorcon/telnet"
+	"github.com/gorcon/telnet/telnettest"
+)
+
+// natHandler answers as a server behind NAT, which sees another client
+// address, and interleaves a background log line with the output.
+func natHandler(c *telnettest.Context) {
+	c.Writer().WriteString(fmt.Sprintf("2020-11-14T23:09:20 31220.643 "+telnet.ResponseINFLayout, c.Request(), "203.0.113.1:54321") + telnet.CRLF)
+	c.Writer().WriteString("line 1" + telnet.CRLF)
+	c.Writer().WriteString("2020-11-14T23:09:20 31220.650 INF Player connected, entityid=171" + telnet.CRLF)
+	c.Writer().WriteString("line 2" + telnet.CRLF)
+	c.Writer().Flush()
+}
+
+func TestConn_ExecuteResult(t *testing.T) {
+	server := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+		telnettest.SetAuthHandler(authHandler),
+		telnettest.SetCommandHandler(natHandler),
+	)
+	defer server.Close()
+
+	conn, err := telnet.Dial(server.Addr(), "password", telnet.SetClearResponse(true))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	t.Run("result", func(t *testing.T) {
+		result, err := conn.ExecuteResult(context.Background(), "lines")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result.Output != "line 1\nline 2" {
+			t.Errorf("got output %q, want %q", result.Output, "line 1\nline 2")
+		}
+
+		if len(result.Logs) != 2 || result.Logs[1].Message != "Player connected, entityid=171" {
+			t.Errorf("got logs %+v, want echo and player connected lines", result.Logs)
+		}
+
+		if len(result.Raw) == 0 || result.Sent.IsZero() || result.Duration <= 0 {
+			t.Errorf("got result %+v, want raw response and timing", result)
+		}
+	})
+
+	t.Run("clear response behind NAT", func(t *testing.T) {
+		result, err := conn.Execute("lines")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		want := "line 1" + telnet.CRLF + "2020-11-14T23:09:20 31220.650 INF Player connected, entityid=171" + telnet.CRLF + "line 2"
+		if result != want {
+			t.Errorf("got result %q, want %q", result, want)
+		}
+	})
+}
+
+func TestParseExecuteResult(t *testing.T) {
+	response := "2020-11-14T23:09:20 31220.643 INF Executing command 'fail' by Telnet from 127.0.0.1:5555\r\n" +
+		"2020-11-14T23:09:20 31220.644 EXC NullReferenceException\r\n" +
+		"  at Console.Execute ()\r\n" +
+		"*** ERROR: Executing command 'fail' failed"
+
+	result := telnet.ParseExecuteResult(nil, "fail", response)
+
+	if result.Output != "*** ERROR: Executing command 'fail' failed" {
+		t.Errorf("got output %q, want the error line", result.Output)
+	}
+
+	if len(result.Logs) != 3 || !result.Logs[2].Continuation {
+		t.Errorf("got logs %+v, want echo, exception and its continuation", result.Logs)
+	}
+
+	if string(result.Raw) != response {
+		t.Errorf("got raw %q, want %q", result.Raw, response)
+	}
+}
+
+func TestParseExecuteResult_BeforeEcho(t *testing.T) {
+	response := "leftover of the previous command\r\n" +
+		"2020-11-14T23:09:20 31220.640 INF Player connected, entityid=171\r\n" +
+		"2020-11-14T23:09:20 31220.643 INF Executing command 'lines' by Telnet from 127.0.0.1:5555\r\n" +
+		"line 1"
+
+	result := telnet.ParseExecuteResult(telnet.SevenDaysDialect{}, "lines", response)
+
+	if result.Output != "line 1" {
+		t.Errorf("got output %q, want %q", result.Output, "line 1")
+	}
+
+	if len(result.Logs) != 3 || result.Logs[0].Raw != "leftover of the previous command" {
+		t.Errorf("got logs %+v, want leftover, player connected and echo lines", result.Logs)
+	}
+}
@@ -0,0 +1,163 @@
+package telnet
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// versionRegexp matches versions like "Alpha 18.4 (b4)" or "V 1.0 (b333)".
+var versionRegexp = regexp.MustCompile(`^(\S+)\s+(\d+)(?:\.(\d+))?(?:\s+\(b(\d+)\))?`)
+
+// Version is the game version.
+type Version struct {
+	// Stage is the release stage, such as "Alpha".
+	Stage string
+	Major int
+	Minor int
+	Build int
+	Raw   string
+}
+
+// String returns the version as it was received.
+func (v Version) String() string {
+	return v.Raw
+}
+
+// ServerInfo contains server information sent in the welcome message
+// after auth.
+type ServerInfo struct {
+	Version              Version
+	CompatibilityVersion Version
+	IP                   string
+	Port                 int
+	MaxPlayers           int
+	GameMode             string
+	World                string
+	GameName             string
+	Difficulty           int
+
+	// Extra contains the key/value lines unknown to the parser.
+	Extra map[string]string
+}
+
+// ServerInfo returns the server information parsed from Status.
+func (c *Conn) ServerInfo() (ServerInfo, error) {
+	return ParseServerInfo(c.Status())
+}
+
+// ParseServerInfo parses the welcome message sent by the server after auth.
+// ErrUnexpectedResponse is returned if the message has neither the version
+// nor any known server information.
+func ParseServerInfo(status string) (ServerInfo, error) {
+	info := ServerInfo{Extra: make(map[string]string)}
+
+	var found bool
+
+	for _, line := range strings.Split(status, "\n") {
+		line = strings.TrimSpace(line)
+
+		if strings.HasPrefix(line, "*** Server version:") {
+			if err := info.parseVersions(line); err != nil {
+				return info, err
+			}
+
+			found = true
+
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.HasPrefix(line, "***") {
+			continue
+		}
+
+		known, err := info.set(strings.TrimSpace(key), strings.TrimSpace(value))
+		if err != nil {
+			return info, err
+		}
+
+		found = found || known
+	}
+
+	if !found {
+		return ServerInfo{}, ErrUnexpectedResponse
+	}
+
+	return info, nil
+}
+
+// parseVersions parses the line like
+// "*** Server version: Alpha 18.4 (b4) Compatibility Version: Alpha 18.4".
+func (info *ServerInfo) parseVersions(line string) error {
+	line = strings.TrimSpace(strings.TrimPrefix(line, "*** Server version:"))
+	server, compatibility, _ := strings.Cut(line, "Compatibility Version:")
+
+	var err error
+	if info.Version, err = parseVersion(strings.TrimSpace(server)); err != nil {
+		return err
+	}
+
+	if compatibility = strings.TrimSpace(compatibility); compatibility != "" {
+		if info.CompatibilityVersion, err = parseVersion(compatibility); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// set sets the field associated with key. It reports whether key is known,
+// the unknown keys are stored in Extra.
+func (info *ServerInfo) set(key string, value string) (bool, error) {
+	var err error
+
+	switch key {
+	case "Server IP":
+		info.IP = value
+	case "Server port":
+		info.Port, err = strconv.Atoi(value)
+	case "Max players":
+		info.MaxPlayers, err = strconv.Atoi(value)
+	case "Game mode":
+		info.GameMode = value
+	case "World":
+		info.World = value
+	case "Game name":
+		info.GameName = value
+	case "Difficulty":
+		info.Difficulty, err = strconv.Atoi(value)
+	default:
+		info.Extra[key] = value
+
+		return false, nil
+	}
+
+	if err != nil {
+		return true, fmt.Errorf("telnet: invalid %s: %w", strings.ToLower(key), err)
+	}
+
+	return true, nil
+}
+
+// parseVersion parses version like "Alpha 18.4 (b4)".
+func parseVersion(raw string) (Version, error) {
+	matches := versionRegexp.FindStringSubmatch(raw)
+	if matches == nil {
+		return Version{Raw: raw}, fmt.Errorf("telnet: invalid version %q", raw)
+	}
+
+	version := Version{Stage: matches[1], Raw: raw}
+	version.Major, _ = strconv.Atoi(matches[2])
+
+	if matches[3] != "" {
+		version.Minor, _ = strconv.Atoi(matches[3])
+	}
+
+	if matches[4] != "" {
+		version.Build, _ = strconv.Atoi(matches[4])
+	}
+
+	return version, nil
+}
@@ -0,0 +1,93 @@
+package telnet_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorcon/telnet"
+	"github.com/gorcon/telnet/telnettest"
+)
+
+func TestParseServerInfo(t *testing.T) {
+	t.Run("welcome message", func(t *testing.T) {
+		want := telnet.ServerInfo{
+			Version:              telnet.Version{Stage: "Alpha", Major: 18, Minor: 4, Build: 4, Raw: "Alpha 18.4 (b4)"},
+			CompatibilityVersion: telnet.Version{Stage: "Alpha", Major: 18, Minor: 4, Raw: "Alpha 18.4"},
+			IP:                   "127.0.0.1",
+			Port:                 26900,
+			MaxPlayers:           8,
+			GameMode:             "GameModeSurvival",
+			World:                "Navezgane",
+			GameName:             "My Game",
+			Difficulty:           2,
+			Extra:                map[string]string{},
+		}
+
+		status := strings.ReplaceAll(telnettest.AuthSuccessWelcomeMessage, "\n", telnet.CRLF)
+
+		got, err := telnet.ParseServerInfo(status)
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("extra lines", func(t *testing.T) {
+		got, err := telnet.ParseServerInfo("Server port: 26900\nBlood moon: 7")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if got.Extra["Blood moon"] != "7" {
+			t.Errorf("got extra %v, want %q", got.Extra, "Blood moon: 7")
+		}
+	})
+
+	t.Run("invalid port", func(t *testing.T) {
+		if _, err := telnet.ParseServerInfo("Server port: abc"); err == nil {
+			t.Error("got nil err, want error")
+		}
+	})
+
+	t.Run("unexpected response", func(t *testing.T) {
+		for _, status := range []string{"", "Welcome, admin", "Blood moon: 7"} {
+			if _, err := telnet.ParseServerInfo(status); !errors.Is(err, telnet.ErrUnexpectedResponse) {
+				t.Errorf("got err %q, want %q for %q", err, telnet.ErrUnexpectedResponse, status)
+			}
+		}
+	})
+
+	t.Run("invalid version", func(t *testing.T) {
+		if _, err := telnet.ParseServerInfo("*** Server version: unknown"); err == nil {
+			t.Error("got nil err, want error")
+		}
+	})
+}
+
+func TestConn_ServerInfo(t *testing.T) {
+	server := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+		telnettest.SetAuthHandler(authHandler),
+	)
+	defer server.Close()
+
+	conn, err := telnet.Dial(server.Addr(), "password")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	info, err := conn.ServerInfo()
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if info.Port != 26900 || info.World != "Navezgane" || info.Version.Major != 18 {
+		t.Errorf("got %+v, want parsed welcome message", info)
+	}
+}
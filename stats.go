@@ -0,0 +1,259 @@
+package telnet
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds of the Latency.Buckets.
+var LatencyBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// Error types counted in Stats.Errors.
+const (
+	ErrorTypeCanceled       = "canceled"
+	ErrorTypeTimeout        = "timeout"
+	ErrorTypeConnectionLost = "connection_lost"
+	ErrorTypeTooLarge       = "too_large"
+	ErrorTypeCommand        = "command"
+	ErrorTypeOther          = "other"
+)
+
+// Metrics receives Conn events. It is used to export client side metrics,
+// for example to Prometheus or expvar. Methods are called synchronously
+// and must not block.
+type Metrics interface {
+	// Executed is called when command execution is finished.
+	Executed(command string, duration time.Duration, err error)
+
+	// Written is called when n bytes are sent to the server.
+	Written(n int)
+
+	// Read is called when n bytes are received from the server.
+	Read(n int)
+
+	// Reconnected is called when the connection is restored,
+	// see SetReconnect.
+	Reconnected()
+}
+
+// Stats is a snapshot of Conn statistics.
+type Stats struct {
+	// Commands is the number of executed commands including failed ones.
+	Commands uint64
+
+	// Errors is the number of failed commands by error type,
+	// see ErrorType constants.
+	Errors map[string]uint64
+
+	BytesIn  uint64
+	BytesOut uint64
+
+	// Reconnects is the number of restored connections.
+	Reconnects uint64
+
+	// DroppedBytes is the number of received bytes discarded because
+	// of the maximum buffer size, see SetMaxBufferSize.
+	DroppedBytes uint64
+
+	// Latency is the latency distribution by command name, the first word
+	// of the command.
+	Latency map[string]Latency
+}
+
+// Latency is a command latency distribution.
+type Latency struct {
+	Count uint64
+	Sum   time.Duration
+	Min   time.Duration
+	Max   time.Duration
+
+	// Buckets holds the number of commands executed within the respective
+	// LatencyBuckets bound, the last one counts the rest.
+	Buckets []uint64
+}
+
+// Mean returns the average command latency.
+func (l Latency) Mean() time.Duration {
+	if l.Count == 0 {
+		return 0
+	}
+
+	return l.Sum / time.Duration(l.Count)
+}
+
+// stats collects Conn statistics. Zero value is ready to use.
+type stats struct {
+	bytesIn    atomic.Uint64
+	bytesOut   atomic.Uint64
+	reconnects atomic.Uint64
+
+	mu       sync.Mutex
+	commands uint64
+	errors   map[string]uint64
+	latency  map[string]*Latency
+}
+
+// executed records the command execution result.
+func (s *stats) executed(command string, duration time.Duration, err error) {
+	name, _, _ := strings.Cut(command, " ")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.commands++
+
+	if err != nil {
+		if s.errors == nil {
+			s.errors = make(map[string]uint64)
+		}
+
+		s.errors[errorType(err)]++
+	}
+
+	if s.latency == nil {
+		s.latency = make(map[string]*Latency)
+	}
+
+	l, ok := s.latency[name]
+	if !ok {
+		l = &Latency{Buckets: make([]uint64, len(LatencyBuckets)+1)}
+		s.latency[name] = l
+	}
+
+	if l.Count == 0 || duration < l.Min {
+		l.Min = duration
+	}
+
+	if duration > l.Max {
+		l.Max = duration
+	}
+
+	l.Count++
+	l.Sum += duration
+
+	i := 0
+	for i < len(LatencyBuckets) && duration > LatencyBuckets[i] {
+		i++
+	}
+
+	l.Buckets[i]++
+}
+
+// snapshot returns a copy of the collected statistics.
+func (s *stats) snapshot() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := Stats{
+		Commands:   s.commands,
+		Errors:     make(map[string]uint64, len(s.errors)),
+		BytesIn:    s.bytesIn.Load(),
+		BytesOut:   s.bytesOut.Load(),
+		Reconnects: s.reconnects.Load(),
+		Latency:    make(map[string]Latency, len(s.latency)),
+	}
+
+	for k, v := range s.errors {
+		snapshot.Errors[k] = v
+	}
+
+	for k, v := range s.latency {
+		l := *v
+		l.Buckets = append([]uint64(nil), v.Buckets...)
+		snapshot.Latency[k] = l
+	}
+
+	return snapshot
+}
+
+// errorType returns the Stats.Errors key for err.
+func errorType(err error) string {
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ErrorTypeCanceled
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, ErrResponseTimeout),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorTypeTimeout
+	case errors.Is(err, ErrConnectionLost):
+		return ErrorTypeConnectionLost
+	case errors.Is(err, ErrResponseTooLarge):
+		return ErrorTypeTooLarge
+	case errors.Is(err, ErrUnknownCommand), errors.Is(err, ErrCommandFailed):
+		return ErrorTypeCommand
+	default:
+		return ErrorTypeOther
+	}
+}
+
+// Stats returns a snapshot of the connection statistics. Statistics are
+// kept across reconnections.
+func (c *Conn) Stats() Stats {
+	stats := c.stats.snapshot()
+	stats.DroppedBytes = c.buffer.Dropped()
+
+	return stats
+}
+
+// executed records the command execution result.
+func (c *Conn) executed(command string, duration time.Duration, err error) {
+	c.stats.executed(command, duration, err)
+
+	if c.settings.metrics != nil {
+		c.settings.metrics.Executed(command, duration, err)
+	}
+}
+
+// written records n bytes sent to the server.
+func (c *Conn) written(n int) {
+	if n <= 0 {
+		return
+	}
+
+	c.stats.bytesOut.Add(uint64(n))
+
+	if c.settings.metrics != nil {
+		c.settings.metrics.Written(n)
+	}
+}
+
+// received records n bytes received from the server.
+func (c *Conn) received(n int) {
+	if n <= 0 {
+		return
+	}
+
+	c.stats.bytesIn.Add(uint64(n))
+
+	if c.settings.metrics != nil {
+		c.settings.metrics.Read(n)
+	}
+}
+
+// reconnected records the restored connection.
+func (c *Conn) reconnected() {
+	c.stats.reconnects.Add(1)
+
+	if c.settings.metrics != nil {
+		c.settings.metrics.Reconnected()
+	}
+}
@@ -0,0 +1,106 @@
+package telnet_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorcon/telnet"
+	"github.com/gorcon/telnet/telnettest"
+)
+
+// countingMetrics counts Metrics calls.
+type countingMetrics struct {
+	mu       sync.Mutex
+	executed []string
+	written  int
+	read     int
+}
+
+func (m *countingMetrics) Executed(command string, _ time.Duration, _ error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.executed = append(m.executed, command)
+}
+
+func (m *countingMetrics) Written(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.written += n
+}
+
+func (m *countingMetrics) Read(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.read += n
+}
+
+func (m *countingMetrics) Reconnected() {}
+
+func TestConn_Stats(t *testing.T) {
+	server := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+		telnettest.SetAuthHandler(authHandler),
+		telnettest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	metrics := &countingMetrics{}
+
+	conn, err := telnet.Dial(server.Addr(), "password", telnet.SetMetrics(metrics))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	for _, command := range []string{"echo one", "echo two", "help"} {
+		if _, err := conn.Execute(command); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := conn.ExecuteContext(ctx, "help"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got err %q, want %q", err, context.Canceled)
+	}
+
+	stats := conn.Stats()
+
+	if stats.Commands != 4 {
+		t.Errorf("got commands %d, want %d", stats.Commands, 4)
+	}
+
+	if got := stats.Errors[telnet.ErrorTypeCanceled]; got != 1 {
+		t.Errorf("got canceled errors %d, want %d", got, 1)
+	}
+
+	if got := stats.Latency["echo"]; got.Count != 2 || got.Min > got.Max || got.Mean() == 0 {
+		t.Errorf("got echo latency %+v, want 2 commands", got)
+	}
+
+	if got := stats.Latency["help"]; got.Count != 2 || len(got.Buckets) != len(telnet.LatencyBuckets)+1 {
+		t.Errorf("got help latency %+v, want 2 commands", got)
+	}
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+
+	if len(metrics.executed) != 4 {
+		t.Errorf("got executed %v, want %d commands", metrics.executed, 4)
+	}
+
+	if stats.BytesOut == 0 || uint64(metrics.written) != stats.BytesOut {
+		t.Errorf("got written %d, want %d", metrics.written, stats.BytesOut)
+	}
+
+	if stats.BytesIn == 0 || uint64(metrics.read) < stats.BytesIn {
+		t.Errorf("got read %d, want at least %d", metrics.read, stats.BytesIn)
+	}
+}
@@ -0,0 +1,100 @@
+package telnet
+
+import (
+	"context"
+	"errors"
+	"iter"
+)
+
+// errStopped stops the stream when the iteration is stopped.
+var errStopped = errors.New("stream stopped")
+
+// ExecuteStream sends command string to execute to the remote TELNET server
+// and calls fn for each response line as it is received, so the response
+// is never kept in memory. The command echo line is skipped.
+//
+// fn is called from the connection reader goroutine and must not execute
+// commands on the same Conn. If fn returns an error, the rest of the response
+// is discarded and the error is returned once the response is complete.
+// If the server reports a console error, *CommandError is returned.
+// If no line is received during the execute timeout, ErrResponseTimeout
+// is returned.
+func (c *Conn) ExecuteStream(ctx context.Context, command string, fn func(line string) error) error {
+	var (
+		echoed bool
+		fnErr  error
+	)
+
+	scanner := errorScanner{dialect: c.settings.dialect, command: command}
+
+	_, err := c.executeCommand(ctx, command, func(line string) {
+		scanner.scan(line)
+
+		if fnErr != nil {
+			return
+		}
+
+		if !echoed && c.settings.dialect.IsEcho(line, command) {
+			echoed = true
+
+			return
+		}
+
+		fnErr = fn(line)
+	})
+
+	// The stream is released before executeCommand returns, so fnErr and
+	// scanner are not changed anymore.
+	switch {
+	case err != nil:
+		return err
+	case fnErr != nil:
+		return fnErr
+	default:
+		return scanner.err()
+	}
+}
+
+// ExecuteLines sends command string to execute to the remote TELNET server
+// and returns an iterator over the response lines as they are received, see
+// ExecuteStream. The command is sent when the iteration starts. The lines
+// are yielded in the caller goroutine. If the iteration is stopped early,
+// it waits for the rest of the response to be discarded. The returned
+// function reports the error after the iteration.
+func (c *Conn) ExecuteLines(ctx context.Context, command string) (iter.Seq[string], func() error) {
+	var err error
+
+	seq := func(yield func(string) bool) {
+		lines := make(chan string)
+		stop := make(chan struct{})
+		result := make(chan error, 1)
+
+		go func() {
+			defer close(lines)
+
+			result <- c.ExecuteStream(ctx, command, func(line string) error {
+				select {
+				case lines <- line:
+					return nil
+				case <-stop:
+					return errStopped
+				}
+			})
+		}()
+
+		for line := range lines {
+			if !yield(line) {
+				// The stream discards the rest of the response.
+				close(stop)
+
+				break
+			}
+		}
+
+		if err = <-result; errors.Is(err, errStopped) {
+			err = nil
+		}
+	}
+
+	return seq, func() error { return err }
+}
@@ -0,0 +1,184 @@
+package telnet_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorcon/telnet"
+	"github.com/gorcon/telnet/telnettest"
+)
+
+func TestConn_ExecuteStream(t *testing.T) {
+	server := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+		telnettest.SetAuthHandler(authHandler),
+		telnettest.SetCommandHandler(bulkHandler),
+	)
+	defer server.Close()
+
+	framings := map[string]telnet.Framing{"quiet": telnet.FramingQuiet, "sentinel": telnet.FramingSentinel}
+
+	for name, framing := range framings {
+		t.Run(name, func(t *testing.T) {
+			testExecuteStream(t, server, framing)
+		})
+	}
+}
+
+func testExecuteStream(t *testing.T, server *telnettest.Server, framing telnet.Framing) {
+	t.Helper()
+
+	conn, err := telnet.Dial(server.Addr(), "password", telnet.SetFraming(framing))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	t.Run("all lines", func(t *testing.T) {
+		var lines int
+
+		err := conn.ExecuteStream(context.Background(), "bulk 1000000", func(line string) error {
+			if line != strings.Repeat("x", 100) {
+				return fmt.Errorf("got line %q, want %d x", line, 100)
+			}
+
+			lines++
+
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if want := 1000000/102 + 1; lines != want {
+			t.Fatalf("got %d lines, want %d", lines, want)
+		}
+	})
+
+	t.Run("stop early", func(t *testing.T) {
+		errStop := errors.New("stop")
+
+		var lines int
+
+		err := conn.ExecuteStream(context.Background(), "bulk 100000", func(line string) error {
+			if lines++; lines == 10 {
+				return errStop
+			}
+
+			return nil
+		})
+		if !errors.Is(err, errStop) {
+			t.Fatalf("got err %q, want %q", err, errStop)
+		}
+
+		if lines != 10 {
+			t.Fatalf("got %d lines, want %d", lines, 10)
+		}
+
+		// The rest of the response doesn't leak into the next command.
+		result, err := conn.Execute("help")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if !strings.HasSuffix(result, "lorem ipsum dolor sit amet") || strings.Contains(result, "xxx") {
+			t.Fatalf("got result %q, want help response", result)
+		}
+	})
+
+	t.Run("command error", func(t *testing.T) {
+		err := conn.ExecuteStream(context.Background(), "random", func(line string) error { return nil })
+		if !errors.Is(err, telnet.ErrUnknownCommand) {
+			t.Fatalf("got err %q, want %q", err, telnet.ErrUnknownCommand)
+		}
+	})
+
+	t.Run("lines iterator", func(t *testing.T) {
+		lines, errFn := conn.ExecuteLines(context.Background(), "help")
+
+		var got []string
+		for line := range lines {
+			got = append(got, line)
+		}
+
+		if err := errFn(); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if len(got) != 1 || got[0] != "lorem ipsum dolor sit amet" {
+			t.Fatalf("got lines %q, want help response", got)
+		}
+	})
+
+	t.Run("lines iterator break", func(t *testing.T) {
+		lines, errFn := conn.ExecuteLines(context.Background(), "bulk 100000")
+
+		var got int
+		for range lines {
+			if got++; got == 5 {
+				break
+			}
+		}
+
+		if err := errFn(); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if got != 5 {
+			t.Fatalf("got %d lines, want %d", got, 5)
+		}
+	})
+}
+
+func TestConn_ExecuteStream_Timeout(t *testing.T) {
+	server := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+		telnettest.SetAuthHandler(authHandler),
+		telnettest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	conn, err := telnet.Dial(server.Addr(), "password", telnet.SetExecuteTimeout(250*time.Millisecond))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	t.Run("lines arriving", func(t *testing.T) {
+		var lines []string
+
+		err := conn.ExecuteStream(context.Background(), "ticking", func(line string) error {
+			lines = append(lines, line)
+
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if len(lines) != 5 || lines[4] != "tick 5" {
+			t.Fatalf("got lines %q, want 5 ticks", lines)
+		}
+	})
+
+	t.Run("silence", func(t *testing.T) {
+		var lines []string
+
+		err := conn.ExecuteStream(context.Background(), "slowish", func(line string) error {
+			lines = append(lines, line)
+
+			return nil
+		})
+		if !errors.Is(err, telnet.ErrResponseTimeout) {
+			t.Fatalf("got err %q, want %q", err, telnet.ErrResponseTimeout)
+		}
+
+		if len(lines) != 1 || lines[0] != "first" {
+			t.Fatalf("got lines %q, want %q", lines, []string{"first"})
+		}
+	})
+}
@@ -2,12 +2,14 @@ package telnet
 
 import (
 	"bufio"
-	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -33,9 +35,31 @@ const NullString = "\x00"
 // ReceiveWaitPeriod is a delay to receive data from the server.
 const ReceiveWaitPeriod = 3 * time.Millisecond
 
-// ExecuteTickTimeout is execute read timeout.
+// ReadBufferSize is the size of the chunks data is read from the server.
+const ReadBufferSize = 32 * 1024
+
+// maxEmptyReads is the number of reads without data and error after which
+// the reader is considered broken.
+const maxEmptyReads = 100
+
+// ExecuteTickTimeout is execute read timeout used by FramingSleep.
 const ExecuteTickTimeout = 1 * time.Second
 
+// DefaultQuietPeriod provides default period of silence after which
+// the response is considered complete.
+const DefaultQuietPeriod = 100 * time.Millisecond
+
+// DefaultMaxBufferSize provides default maximum size of a command response.
+const DefaultMaxBufferSize = 64 << 20
+
+// DefaultExecuteTimeout provides default maximum time to wait for
+// the response to be complete.
+const DefaultExecuteTimeout = 10 * time.Second
+
+// SentinelCommand is sent after the executed command by FramingSentinel.
+// Any unknown command is suitable, the server answers it with an error.
+const SentinelCommand = "gorcon-telnet-sentinel"
+
 // Remote server response messages.
 const (
 	ResponseEnterPassword         = "Please enter password"
@@ -54,11 +78,20 @@ var (
 	// sent password.
 	ErrAuthFailed = errors.New("authentication failed")
 
+	// ErrAuthLockedOut is returned when the server refused to authenticate
+	// because of too many failed attempts. It matches ErrAuthFailed.
+	ErrAuthLockedOut = fmt.Errorf("%w: too many failed attempts", ErrAuthFailed)
+
 	// ErrAuthUnexpectedMessage is returned when 7 Days to Die server responses
 	// without ResponseAuthSuccess or ResponseAuthIncorrectPassword
 	// on auth request.
 	ErrAuthUnexpectedMessage = errors.New("unexpected authentication response")
 
+	// ErrInsecureAuth is returned when the password is not sent because
+	// the connection is not encrypted and the server is not on the local
+	// host, see SetRefusePlaintextAuth.
+	ErrInsecureAuth = errors.New("refused to send password in plaintext")
+
 	// ErrCommandTooLong is returned when executed command length is bigger
 	// than MaxCommandLen characters.
 	ErrCommandTooLong = errors.New("command too long")
@@ -66,40 +99,169 @@ var (
 	// ErrCommandEmpty is returned when executed command length equal 0.
 	ErrCommandEmpty = errors.New("command too small")
 
+	// ErrCommandInvalid is returned when executed command contains CR, LF
+	// or NUL characters, which would let it run other commands.
+	ErrCommandInvalid = errors.New("command contains invalid characters")
+
+	// ErrUnexpectedResponse is returned when command response can't be parsed.
+	ErrUnexpectedResponse = errors.New("unexpected command response")
+
+	// ErrResponseTimeout is returned along with the partial response when
+	// the response is not complete before the execute timeout, see
+	// SetExecuteTimeout.
+	ErrResponseTimeout = errors.New("response timeout")
+
+	// ErrResponseTooLarge is returned when the command response exceeds
+	// the maximum buffer size, see SetMaxBufferSize.
+	ErrResponseTooLarge = errors.New("response too large")
+
+	// ErrConnectionLost is returned when the connection was closed by
+	// the server before the response was complete or when reconnection
+	// attempts are exhausted. The partial response is returned along
+	// with it.
+	ErrConnectionLost = errors.New("connection lost")
+
 	// ErrMultiErrorOccurred is returned when close connection failed with
 	// error after auth failed.
 	ErrMultiErrorOccurred = errors.New("an error occurred while handling another error")
 )
 
-// Conn is TELNET connection.
+// readBuffers is a pool of buffers for reading data from the server.
+var readBuffers = sync.Pool{
+	New: func() any {
+		packet := make([]byte, ReadBufferSize)
+
+		return &packet
+	},
+}
+
+// Dialer opens network connections. It is implemented by *net.Dialer and
+// proxy dialers, such as golang.org/x/net/proxy.
+type Dialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// Conn is TELNET connection. It is safe for concurrent use by multiple
+// goroutines, commands are queued and executed one at a time.
 type Conn struct {
+	mu       sync.Mutex
 	conn     net.Conn
 	settings Settings
 	reader   io.Reader
 	writer   io.Writer
-	buffer   *bytes.Buffer
+	buffer   *buffer
+	logs     *logHub
+	queue    chan struct{}
+	iac      negotiator
 	status   string
+	stats    stats
+
+	// Reconnection state, see reconnect.go.
+	network      string
+	address      string
+	password     string
+	readDone     chan struct{}
+	reconnecting chan struct{}
+	lost         error
+	ready        bool
+	closed       bool
+	quit         chan struct{}
+
+	// Lifecycle state. The wg counts the reader and reconnection
+	// goroutines, Close waits for them.
+	wg        sync.WaitGroup
+	stopped   chan struct{}
+	err       error
+	closeOnce sync.Once
+	closeErr  error
+
+	// Cached help command result, see Commands.
+	commandsMu         sync.Mutex
+	commands           []CommandInfo
+	commandsReconnects uint64
 }
 
 // Dial creates a new authorized TELNET connection.
 func Dial(address string, password string, options ...Option) (*Conn, error) {
+	return DialContext(context.Background(), address, password, options...)
+}
+
+// DialContext creates a new authorized TELNET connection using the provided
+// context. The context aborts the dial and the auth request. Once the
+// connection is established, the context does not affect it.
+func DialContext(ctx context.Context, address string, password string, options ...Option) (*Conn, error) {
 	settings := DefaultSettings
 
 	for _, option := range options {
 		option(&settings)
 	}
 
-	conn, err := net.DialTimeout("tcp", address, settings.dialTimeout)
+	conn, err := dial(ctx, "tcp", address, settings)
 	if err != nil {
-		// Failed to open TCP conn to the server.
-		return nil, fmt.Errorf("telnet: %w", err)
+		logRecord(settings.logger, password, slog.LevelError, "telnet: dial failed", "address", address, "error", err)
+
+		return nil, err
 	}
 
-	client := Conn{conn: conn, settings: settings, reader: conn, writer: conn, buffer: new(bytes.Buffer)}
+	return newConn(ctx, conn, "tcp", address, password, settings)
+}
+
+// NewConn creates a new authorized TELNET connection over the connection
+// opened by the caller, for example through a tunnel or with net.Pipe.
+// On reconnection, conn.RemoteAddr() is redialed with the configured Dialer.
+func NewConn(conn net.Conn, password string, options ...Option) (*Conn, error) {
+	settings := DefaultSettings
+
+	for _, option := range options {
+		option(&settings)
+	}
 
-	go client.processReadResponse(client.buffer)
+	addr := conn.RemoteAddr()
+
+	conn, err := secure(context.Background(), conn, addr.String(), settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return newConn(context.Background(), conn, addr.Network(), addr.String(), password, settings)
+}
+
+// newConn authorizes conn and starts reading responses from it.
+func newConn(
+	ctx context.Context, conn net.Conn, network string, address string, password string, settings Settings,
+) (*Conn, error) {
+	if settings.framing == framingDialect {
+		settings.framing = settings.dialect.Framing()
+	}
+
+	client := Conn{
+		settings: settings,
+		logs:     newLogHub(settings),
+		queue:    make(chan struct{}, 1),
+		network:  network,
+		address:  address,
+		password: password,
+		quit:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+
+	// Everything before the end of auth is the response to the password.
+	client.buffer = &buffer{
+		capturing: true,
+		onLine:    client.logs.publish,
+		limit:     settings.maxBufferSize,
+		policy:    settings.bufferOverflow,
+	}
+
+	if err := client.attach(conn); err != nil {
+		return nil, err
+	}
+
+	client.log(slog.LevelInfo, "telnet: connected", "address", address)
+
+	if err := client.auth(ctx, password); err != nil {
+		client.log(slog.LevelWarn, "telnet: authentication failed", "address", address, "error", err)
 
-	if err := client.auth(password); err != nil {
 		// Failed to auth conn with the server.
 		if err2 := client.Close(); err2 != nil {
 			//nolint:errorlint // TODO: Come up with the better wrapping
@@ -109,6 +271,13 @@ func Dial(address string, password string, options ...Option) (*Conn, error) {
 		return &client, err
 	}
 
+	client.buffer.Release()
+	client.log(slog.LevelInfo, "telnet: authenticated", "address", address)
+
+	client.mu.Lock()
+	client.ready = true
+	client.mu.Unlock()
+
 	return &client, nil
 }
 
@@ -122,117 +291,540 @@ func DialInteractive(r io.Reader, w io.Writer, address string, password string,
 		option(&settings)
 	}
 
-	conn, err := net.DialTimeout("tcp", address, settings.dialTimeout)
+	conn, err := dial(context.Background(), "tcp", address, settings)
 	if err != nil {
-		// Failed to open TCP conn to the server.
-		return fmt.Errorf("telnet: %w", err)
+		logRecord(settings.logger, password, slog.LevelError, "telnet: dial failed", "address", address, "error", err)
+
+		return err
+	}
+
+	if settings.refusePlaintextAuth && !isSecure(conn, "tcp", address, settings) {
+		// The password is typed in the interactive window otherwise.
+		_ = conn.Close()
+
+		return ErrInsecureAuth
 	}
 
-	client := Conn{conn: conn, settings: settings, reader: conn, writer: conn}
+	client := Conn{
+		conn:     conn,
+		settings: settings,
+		reader:   conn,
+		writer:   conn,
+		iac:      negotiator{handlers: settings.optionHandlers},
+		password: password,
+	}
 	defer client.Close()
 
+	client.log(slog.LevelInfo, "telnet: connected", "address", address)
+
 	if password != "" {
 		if _, err := client.write([]byte(password + CRLF)); err != nil {
 			return err
 		}
 	}
 
-	go client.processReadResponse(w)
+	client.wg.Add(1)
+
+	go func() {
+		defer client.wg.Done()
 
-	return client.interactive(r)
+		_ = client.processReadResponse(conn, w)
+	}()
+
+	return client.interactive(r, password == "")
 }
 
 // Execute sends command string to execute to the remote TELNET server.
 func (c *Conn) Execute(command string) (string, error) {
+	return c.ExecuteContext(context.Background(), command)
+}
+
+// ExecuteContext sends command string to execute to the remote TELNET server
+// using the provided context. If the context is canceled or its deadline
+// is exceeded before the response is received, the wrapped ctx.Err()
+// is returned immediately, and the rest of the response is discarded
+// before the next command is sent. If the response is not complete before
+// the execute timeout, ErrResponseTimeout is returned and the connection
+// is closed. If the server reports a console error, *CommandError is
+// returned along with the response.
+func (c *Conn) ExecuteContext(ctx context.Context, command string) (string, error) {
+	response, err := c.executeCommand(ctx, command, nil)
+
+	// The response of the failed command is returned as is.
+	var commandErr *CommandError
+	if err != nil && !errors.As(err, &commandErr) {
+		return response, err
+	}
+
+	if c.settings.clearResponse {
+		response = cutEcho(c.settings.dialect, command, response)
+	}
+
+	return response, err
+}
+
+// executeCommand executes command and records the result in statistics
+// and logs. If stream is not nil, the response lines are passed to it
+// instead of being returned.
+func (c *Conn) executeCommand(ctx context.Context, command string, stream func(line string)) (string, error) {
 	if command == "" {
 		return "", ErrCommandEmpty
 	}
 
-	response, err := c.execute(command)
-	if err != nil {
-		return response, err
+	start := time.Now()
+
+	c.log(slog.LevelDebug, "telnet: command sent", "command", command)
+
+	response, retry, err := c.executeQueued(ctx, command, stream)
+	if retry {
+		// The command was not sent, send it over the new connection.
+		response, _, err = c.executeQueued(ctx, command, stream)
 	}
 
-	if c.settings.clearResponse {
-		responseINFMessage := fmt.Sprintf(ResponseINFLayout+CRLF, command, c.LocalAddr().String())
-		if tmp := strings.Split(response, responseINFMessage); len(tmp) > 1 {
-			return tmp[1], err
-		}
+	if err == nil {
+		err = commandError(c.settings.dialect, command, response)
+	}
+
+	c.executed(command, time.Since(start), err)
+
+	if err != nil {
+		c.log(slog.LevelWarn, "telnet: command failed", "command", command, "error", err)
+	} else {
+		c.log(slog.LevelDebug, "telnet: command executed",
+			"command", command, "bytes", len(response), "duration", time.Since(start))
 	}
 
 	return response, err
 }
 
+// Subscribe returns a new Subscription to the lines received from the server
+// outside command responses, such as chat messages and player events, and
+// to the log lines received during command responses except the command
+// echo. The caller should call Close on the Subscription when finished.
+func (c *Conn) Subscribe() *Subscription {
+	return c.logs.subscribe()
+}
+
 // LocalAddr returns the local network address.
 func (c *Conn) LocalAddr() net.Addr {
-	return c.conn.LocalAddr()
+	return c.current().LocalAddr()
 }
 
 // RemoteAddr returns the remote network address.
 func (c *Conn) RemoteAddr() net.Addr {
-	return c.conn.RemoteAddr()
+	return c.current().RemoteAddr()
 }
 
 // Status returns server info status after auth request.
 func (c *Conn) Status() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	return c.status
 }
 
-// Close closes the client connection.
+// Done returns a channel that is closed when the connection is closed or
+// lost and is not going to be restored. See Err for the reason.
+func (c *Conn) Done() <-chan struct{} {
+	return c.stopped
+}
+
+// Err returns nil if Done is not yet closed. Otherwise, it returns
+// the reason: net.ErrClosed after Close, ErrConnectionLost joined with
+// the read error or the last reconnection error otherwise.
+func (c *Conn) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.err
+}
+
+// Close closes the client connection and waits for the reader goroutine
+// to finish. It is safe to call Close multiple times and concurrently,
+// all calls return the result of the first one.
 func (c *Conn) Close() error {
+	c.closeOnce.Do(func() {
+		c.closeErr = c.close()
+	})
+
+	return c.closeErr
+}
+
+// close sends the exit command and closes the connection.
+func (c *Conn) close() error {
 	_, _ = c.write([]byte(c.settings.exitCommand + CRLF))
 
 	time.Sleep(ReceiveWaitPeriod)
 
-	return c.conn.Close()
+	c.mu.Lock()
+	if c.quit != nil {
+		close(c.quit)
+	}
+
+	c.closed = true
+	conn := c.conn
+	c.mu.Unlock()
+
+	if c.logs != nil {
+		c.logs.close()
+	}
+
+	c.log(slog.LevelInfo, "telnet: connection closed", "address", conn.RemoteAddr().String())
+
+	err := conn.Close()
+
+	c.wg.Wait()
+	c.stop(nil)
+
+	return err
+}
+
+// stop closes Done channel with the err reason. The reason is net.ErrClosed
+// if the connection is closed by Close. Only the first call has an effect.
+func (c *Conn) stop(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopped == nil || c.err != nil {
+		return
+	}
+
+	if c.closed {
+		c.err = fmt.Errorf("telnet: %w", net.ErrClosed)
+	} else {
+		c.err = fmt.Errorf("telnet: %w: %w", ErrConnectionLost, err)
+	}
+
+	close(c.stopped)
+}
+
+// dial opens a new network connection to address with the configured
+// Dialer.
+func dial(ctx context.Context, network string, address string, settings Settings) (net.Conn, error) {
+	var dialer Dialer = &net.Dialer{Timeout: settings.dialTimeout}
+
+	if settings.dialer != nil {
+		dialer = settings.dialer
+
+		if settings.dialTimeout > 0 {
+			var cancel context.CancelFunc
+
+			ctx, cancel = context.WithTimeout(ctx, settings.dialTimeout)
+			defer cancel()
+		}
+	}
+
+	conn, err := dialer.DialContext(ctx, network, address)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			// Dial was aborted by the context.
+			return nil, fmt.Errorf("telnet: %w", ctxErr)
+		}
+
+		// Failed to open TCP conn to the server.
+		return nil, fmt.Errorf("telnet: %w", err)
+	}
+
+	return secure(ctx, conn, address, settings)
+}
+
+// attach makes conn the current connection and starts reading responses
+// from it.
+func (c *Conn) attach(conn net.Conn) error {
+	done := make(chan struct{})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		_ = conn.Close()
+
+		return fmt.Errorf("telnet: %w", net.ErrClosed)
+	}
+
+	c.conn, c.reader, c.writer, c.readDone = conn, conn, conn, done
+	c.iac = negotiator{handlers: c.settings.optionHandlers}
+
+	c.wg.Add(1)
+
+	go func() {
+		defer c.wg.Done()
+
+		err := c.processReadResponse(conn, c.buffer)
+		close(done)
+
+		if !c.broken(done, err) {
+			c.stop(err)
+		}
+	}()
+
+	return nil
+}
+
+// current returns the current network connection.
+func (c *Conn) current() net.Conn {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.conn
+}
+
+// done returns the channel which is closed when the reader of the current
+// connection stops.
+func (c *Conn) done() chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.readDone
+}
+
+// alive reports whether the connection is able to execute commands.
+func (c *Conn) alive() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed || c.lost != nil {
+		return false
+	}
+
+	if c.reconnecting != nil {
+		return true
+	}
+
+	select {
+	case <-c.readDone:
+		return false
+	default:
+		return true
+	}
+}
+
+// lock takes the place in the command queue. It blocks until the previous
+// commands are executed or ctx is done.
+func (c *Conn) lock(ctx context.Context) error {
+	select {
+	case c.queue <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("telnet: %w", ctx.Err())
+	}
+}
+
+// unlock releases the place in the command queue.
+func (c *Conn) unlock() {
+	<-c.queue
+}
+
+// executeQueued takes the place in the command queue and executes command.
+// It returns true if command was not sent because of the broken connection
+// and may be sent again after reconnection.
+func (c *Conn) executeQueued(ctx context.Context, command string, stream func(line string)) (string, bool, error) {
+	done, err := c.acquire(ctx)
+	if err != nil {
+		return "", false, err
+	}
+
+	if stream != nil {
+		c.buffer.Stream(stream)
+	} else {
+		c.buffer.Capture()
+	}
+
+	c.buffer.Echo(func(line string) bool {
+		return c.settings.dialect.IsOutputStart(line, command)
+	})
+
+	var response string
+
+	sent, err := c.send(ctx, command, c.settings.framing)
+	if err == nil {
+		response, err = c.receive(ctx, c.settings.framing, sent)
+	}
+
+	if !sent.IsZero() && c.abandon(c.settings.framing, sent, err) {
+		// The queue is released when the rest of the response is drained.
+		return response, false, err
+	}
+
+	c.buffer.Release()
+	c.unlock()
+
+	if sent.IsZero() && isWriteError(err) && c.broken(done, err) {
+		return response, true, err
+	}
+
+	return response, false, err
+}
+
+// abandon handles the command which response is not received because of
+// err. The rest of the response is drained in background, so it is not
+// taken by the next command, and abandon returns true. The command queue
+// and the buffer are released when it is done. If the response can't be
+// drained, the connection is closed since it is out of sync.
+func (c *Conn) abandon(framing Framing, sent time.Time, err error) bool {
+	if err == nil || errors.Is(err, ErrConnectionLost) || errors.Is(err, ErrResponseTooLarge) {
+		return false
+	}
+
+	// The caller doesn't wait for the lines anymore.
+	c.buffer.Discard()
+
+	c.mu.Lock()
+	closed := c.closed
+	if !closed && !errors.Is(err, ErrResponseTimeout) {
+		c.wg.Add(1)
+	}
+	c.mu.Unlock()
+
+	if closed {
+		return false
+	}
+
+	if errors.Is(err, ErrResponseTimeout) {
+		// The response didn't end in time and won't be recognised later.
+		c.discard(err)
+
+		return false
+	}
+
+	go func() {
+		defer c.wg.Done()
+		defer c.unlock()
+		defer c.buffer.Release()
+
+		if err := c.wait(context.Background(), framing, sent); err != nil && !errors.Is(err, ErrConnectionLost) {
+			c.discard(err)
+		}
+	}()
+
+	return true
+}
+
+// discard closes the current connection because it is out of sync with
+// the command queue. It is restored if reconnection is enabled.
+func (c *Conn) discard(cause error) {
+	c.log(slog.LevelWarn, "telnet: connection discarded", "error", cause)
+
+	_ = c.current().Close()
 }
 
 // auth authenticates client for the next requests.
-func (c *Conn) auth(password string) error {
-	var err error
-	if c.status, err = c.execute(password); err != nil {
+func (c *Conn) auth(ctx context.Context, password string) error {
+	if c.settings.refusePlaintextAuth && !isSecure(c.current(), c.network, c.address, c.settings) {
+		return ErrInsecureAuth
+	}
+
+	// The password must never be followed by the sentinel.
+	framing := FramingQuiet
+	if c.settings.framing == FramingSleep {
+		framing = FramingSleep
+	} else if err := c.waitFor(ctx, c.settings.dialect.IsPasswordPrompt); err != nil {
+		// The prompt must not be taken as the response to the password.
 		return err
 	}
 
-	if strings.Contains(c.status, ResponseAuthIncorrectPassword) {
-		return ErrAuthFailed
+	// The response to the password has no end mark, the dialect decides
+	// whether the partial response is enough.
+	status, err := c.execute(ctx, password, framing)
+	if err != nil && !errors.Is(err, ErrResponseTimeout) {
+		return err
 	}
 
-	if !strings.Contains(c.status, ResponseAuthSuccess) {
+	c.log(slog.LevelDebug, "telnet: auth response", "response", status)
+
+	switch c.settings.dialect.AuthResult(status) {
+	case AuthSucceeded:
+	case AuthFailed:
+		return ErrAuthFailed
+	case AuthLockedOut:
+		return ErrAuthLockedOut
+	default:
 		return ErrAuthUnexpectedMessage
 	}
 
-	c.status = strings.TrimPrefix(c.status, ResponseEnterPassword+CRLF+ResponseAuthSuccess)
-	c.status = strings.TrimSuffix(c.status, CRLF+CRLF+ResponseWelcome)
-	c.status = strings.TrimSpace(c.status)
+	c.mu.Lock()
+	c.status = c.settings.dialect.Banner(status)
+	c.mu.Unlock()
 
 	return nil
 }
 
 // execute sends command string to execute to the remote TELNET server.
-func (c *Conn) execute(command string) (string, error) {
+// The end of the response is detected according to framing.
+func (c *Conn) execute(ctx context.Context, command string, framing Framing) (string, error) {
+	sent, err := c.send(ctx, command, framing)
+	if err != nil {
+		return "", err
+	}
+
+	return c.receive(ctx, framing, sent)
+}
+
+// send sends command string to execute to the remote TELNET server. It
+// returns the time the command was sent at, which is zero if the command
+// was not sent. The command may be sent along with the context error if
+// the context is done right after the write.
+func (c *Conn) send(ctx context.Context, command string, framing Framing) (time.Time, error) {
 	if len(command) > MaxCommandLen {
-		return "", ErrCommandTooLong
+		return time.Time{}, ErrCommandTooLong
 	}
 
-	if _, err := c.write([]byte(command + CRLF)); err != nil {
-		return "", err
+	if strings.ContainsAny(command, invalidCommandChars) {
+		return time.Time{}, ErrCommandInvalid
 	}
 
-	time.Sleep(ExecuteTickTimeout)
+	request := command + CRLF
+	if framing == FramingSentinel {
+		request += SentinelCommand + CRLF
+	}
+
+	sent := time.Now()
+
+	n, err := c.writeContext(ctx, []byte(request))
+	switch {
+	case err == nil, n == len(request):
+		return sent, err
+	case n > 0:
+		// The rest of the command would be joined with the next one.
+		c.discard(err)
+	}
+
+	return time.Time{}, err
+}
+
+// receive waits for the response to the command sent at the sent time
+// and returns it.
+func (c *Conn) receive(ctx context.Context, framing Framing, sent time.Time) (string, error) {
+	// The partial response is returned along with ErrResponseTimeout
+	// and ErrConnectionLost.
+	err := c.wait(ctx, framing, sent)
+	if err != nil && !errors.Is(err, ErrResponseTimeout) && !errors.Is(err, ErrConnectionLost) {
+		return "", err
+	}
 
-	response := c.buffer.String()
-	*c.buffer = bytes.Buffer{}
+	response, overflowed := c.buffer.Flush()
+	if framing == FramingSentinel {
+		response = cutSentinel(response)
+	}
 
 	response = strings.ReplaceAll(response, NullString, "")
 	response = strings.TrimSpace(response)
 
+	if err != nil {
+		return response, err
+	}
+
+	if overflowed && c.settings.bufferOverflow == FailOnOverflow {
+		return response, fmt.Errorf("telnet: %w", ErrResponseTooLarge)
+	}
+
 	return response, nil
 }
 
 // interactive reads commands from reader in terminal mode and sends them
-// to execute to the remote TELNET server.
-func (c *Conn) interactive(r io.Reader) error {
+// to execute to the remote TELNET server. If prompt is true, the first
+// line is the password and it is never logged.
+func (c *Conn) interactive(r io.Reader, prompt bool) error {
 	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
 		command := scanner.Text()
@@ -245,6 +837,14 @@ func (c *Conn) interactive(r io.Reader) error {
 			return err
 		}
 
+		if prompt {
+			prompt = false
+
+			c.log(slog.LevelDebug, "telnet: command sent", "command", Redacted)
+		} else {
+			c.log(slog.LevelDebug, "telnet: command sent", "command", command)
+		}
+
 		if command == c.settings.exitCommand {
 			break
 		}
@@ -257,28 +857,80 @@ func (c *Conn) interactive(r io.Reader) error {
 
 // write sends data to established TELNET connection.
 func (c *Conn) write(p []byte) (n int, err error) {
-	return c.writer.Write(p)
+	c.mu.Lock()
+	writer := c.writer
+	c.mu.Unlock()
+
+	n, err = writer.Write(p)
+	c.written(n)
+
+	return n, err
 }
 
-// read reads structured binary data from c.conn into byte array.
-func (c *Conn) read(p []byte) (n int, err error) {
-	return c.reader.Read(p)
+// writeContext sends data to established TELNET connection and aborts
+// the write when ctx is done.
+func (c *Conn) writeContext(ctx context.Context, p []byte) (n int, err error) {
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("telnet: %w", err)
+	}
+
+	conn := c.current()
+
+	stop := context.AfterFunc(ctx, func() {
+		// Unblock the pending write.
+		_ = conn.SetWriteDeadline(time.Unix(1, 0))
+	})
+
+	n, err = c.write(p)
+	if !stop() {
+		// The context is done and the write deadline was moved to the past.
+		_ = conn.SetWriteDeadline(time.Time{})
+
+		return n, fmt.Errorf("telnet: %w", ctx.Err())
+	}
+
+	return n, err
 }
 
 // processReadResponse reads response data from TELNET connection
-// and writes them to writer (Stdout).
-func (c *Conn) processReadResponse(writer io.Writer) {
-	packet := make([]byte, 1)
+// and writes them to writer (Stdout). TELNET command sequences are
+// answered and never written to writer. It returns the read error.
+//
+// Data is read in chunks of ReadBufferSize bytes into pooled buffers
+// and passed to writer once per chunk, writer is responsible for
+// splitting it into lines.
+func (c *Conn) processReadResponse(reader io.Reader, writer io.Writer) error {
+	packet := readBuffers.Get().(*[]byte)
+	defer readBuffers.Put(packet)
+
+	empty := 0
 
 	for {
-		// Read 1 byte.
-		n, err := c.read(packet)
-		if n <= 0 && err == nil {
-			continue
-		} else if n <= 0 && err != nil {
-			break
+		n, err := reader.Read(*packet)
+		if n > 0 {
+			empty = 0
+
+			c.received(n)
+
+			data, reply := c.iac.filter((*packet)[:n])
+			if len(reply) > 0 {
+				_, _ = c.write(reply)
+			}
+
+			if len(data) > 0 {
+				_, _ = writer.Write(data)
+			}
 		}
 
-		_, _ = writer.Write(packet)
+		if err != nil {
+			return err
+		}
+
+		if n == 0 {
+			// Do not spin forever on a broken reader.
+			if empty++; empty >= maxEmptyReads {
+				return io.ErrNoProgress
+			}
+		}
 	}
 }
@@ -1,11 +1,18 @@
 package telnet_test
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"net"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -32,11 +39,77 @@ func authHandler(c *telnettest.Context) {
 }
 
 func commandHandler(c *telnettest.Context) {
-	switch c.Request() {
-	case "", "exit":
-	case "help":
+	switch request := c.Request(); {
+	case strings.HasPrefix(request, "echo "):
+		c.Writer().WriteString(fmt.Sprintf("2020-11-14T23:09:20 31220.643 "+telnet.ResponseINFLayout, request, c.Conn().RemoteAddr()) + telnet.CRLF)
+		c.Writer().WriteString(strings.TrimPrefix(request, "echo ") + telnet.CRLF)
+	case request == "", request == "exit":
+	case request == "help":
 		c.Writer().WriteString(fmt.Sprintf("2020-11-14T23:09:20 31220.643 "+telnet.ResponseINFLayout, c.Request(), c.Conn().RemoteAddr()) + telnet.CRLF)
 		c.Writer().WriteString("lorem ipsum dolor sit amet" + telnet.CRLF)
+	case request == telnet.CommandListPlayers:
+		c.Writer().WriteString(fmt.Sprintf("2020-11-14T23:09:20 31220.643 "+telnet.ResponseINFLayout, c.Request(), c.Conn().RemoteAddr()) + telnet.CRLF)
+		c.Writer().WriteString(listPlayersResponse + telnet.CRLF)
+	case request == telnet.CommandMemory:
+		c.Writer().WriteString(fmt.Sprintf("2020-11-14T23:09:20 31220.643 "+telnet.ResponseINFLayout, c.Request(), c.Conn().RemoteAddr()) + telnet.CRLF)
+		c.Writer().WriteString(memResponse + telnet.CRLF)
+	case request == "chat":
+		c.Writer().WriteString(fmt.Sprintf("2020-11-14T23:09:20 31220.643 "+telnet.ResponseINFLayout, c.Request(), c.Conn().RemoteAddr()) + telnet.CRLF)
+		c.Writer().WriteString("ok" + telnet.CRLF)
+		c.Writer().Flush()
+		time.Sleep(200 * time.Millisecond)
+
+		for i := 1; i <= 3; i++ {
+			c.Writer().WriteString(fmt.Sprintf("2020-11-14T23:09:21 31221.643 INF Chat (from 'Steam_1', entity id '171', to 'Global'): 'Player': hello %d", i) + telnet.CRLF)
+		}
+	case request == "interleaved":
+		c.Writer().WriteString(fmt.Sprintf("2020-11-14T23:09:20 31220.643 "+telnet.ResponseINFLayout, c.Request(), c.Conn().RemoteAddr()) + telnet.CRLF)
+		c.Writer().WriteString("line 1" + telnet.CRLF)
+		c.Writer().WriteString("2020-11-14T23:09:20 31220.650 INF Player connected, entityid=171" + telnet.CRLF)
+		c.Writer().WriteString("line 2" + telnet.CRLF)
+	case request == "noisy":
+		c.Writer().WriteString("2020-11-14T23:09:20 31220.640 EXC IOException: Sharing violation on path" + telnet.CRLF)
+		c.Writer().WriteString(fmt.Sprintf("2020-11-14T23:09:20 31220.643 "+telnet.ResponseINFLayout, c.Request(), c.Conn().RemoteAddr()) + telnet.CRLF)
+		c.Writer().WriteString("ok" + telnet.CRLF)
+		c.Writer().WriteString("2020-11-14T23:09:21 31221.643 INF Chat (from 'Steam_1', entity id '171', to 'Global'): 'Player': *** ERROR: lag" + telnet.CRLF)
+		c.Writer().WriteString("2020-11-14T23:09:21 31221.650 EXC NullReferenceException: Object reference not set to an instance of an object" + telnet.CRLF)
+	case request == "marked":
+		c.Writer().WriteString(fmt.Sprintf("2020-11-14T23:09:20 31220.643 "+telnet.ResponseINFLayout, c.Request(), c.Conn().RemoteAddr()) + telnet.CRLF)
+
+		for i := 1; i <= 300; i++ {
+			c.Writer().WriteString(fmt.Sprintf("line %d", i) + telnet.CRLF)
+		}
+
+		c.Writer().WriteString("MARK done" + telnet.CRLF)
+	case request == "fail":
+		c.Writer().WriteString(fmt.Sprintf("2020-11-14T23:09:20 31220.643 "+telnet.ResponseINFLayout, c.Request(), c.Conn().RemoteAddr()) + telnet.CRLF)
+		c.Writer().WriteString("2020-11-14T23:09:20 31220.644 EXC NullReferenceException: Object reference not set to an instance of an object" + telnet.CRLF)
+		c.Writer().WriteString("  at Console.Execute () [0x00000] in <filename unknown>:0 " + telnet.CRLF)
+	case request == "slow":
+		c.Writer().WriteString(fmt.Sprintf("2020-11-14T23:09:20 31220.643 "+telnet.ResponseINFLayout, c.Request(), c.Conn().RemoteAddr()) + telnet.CRLF)
+		c.Writer().Flush()
+		time.Sleep(300 * time.Millisecond)
+		c.Writer().WriteString("done" + telnet.CRLF)
+	case request == "slowish":
+		c.Writer().WriteString(fmt.Sprintf("2020-11-14T23:09:20 31220.643 "+telnet.ResponseINFLayout, c.Request(), c.Conn().RemoteAddr()) + telnet.CRLF)
+		c.Writer().WriteString("first" + telnet.CRLF)
+		c.Writer().Flush()
+		time.Sleep(400 * time.Millisecond)
+		c.Writer().WriteString("second" + telnet.CRLF)
+	case request == "ticking":
+		c.Writer().WriteString(fmt.Sprintf("2020-11-14T23:09:20 31220.643 "+telnet.ResponseINFLayout, c.Request(), c.Conn().RemoteAddr()) + telnet.CRLF)
+
+		for i := 1; i <= 5; i++ {
+			c.Writer().WriteString(fmt.Sprintf("tick %d", i) + telnet.CRLF)
+			c.Writer().Flush()
+			time.Sleep(100 * time.Millisecond)
+		}
+	case request == "late":
+		c.Writer().WriteString("2020-11-14T23:09:19 31219.643 INF Player connected, entityid=171" + telnet.CRLF)
+		c.Writer().Flush()
+		time.Sleep(200 * time.Millisecond)
+		c.Writer().WriteString(fmt.Sprintf("2020-11-14T23:09:20 31220.643 "+telnet.ResponseINFLayout, c.Request(), c.Conn().RemoteAddr()) + telnet.CRLF)
+		c.Writer().WriteString("done" + telnet.CRLF)
 	default:
 		c.Writer().WriteString(fmt.Sprintf("*** ERROR: unknown command '%s'", c.Request()) + telnet.CRLF)
 	}
@@ -95,6 +168,199 @@ func TestDial(t *testing.T) {
 	})
 }
 
+func TestDialContext(t *testing.T) {
+	server := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password", AuthResponseDelay: 200 * time.Millisecond}),
+		telnettest.SetAuthHandler(authHandler),
+	)
+	defer server.Close()
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := telnet.DialContext(ctx, server.Addr(), "password")
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got err %q, want %q", err, context.Canceled)
+		}
+	})
+
+	t.Run("auth deadline exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err := telnet.DialContext(ctx, server.Addr(), "password")
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got err %q, want %q", err, context.DeadlineExceeded)
+		}
+
+		if err == nil || !strings.HasPrefix(err.Error(), "telnet: ") {
+			t.Errorf("got err %q, want to have prefix %q", err, "telnet: ")
+		}
+	})
+
+	t.Run("auth success", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		conn, err := telnet.DialContext(ctx, server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if conn.Status() != telnettest.AuthSuccessWelcomeMessage {
+			t.Fatalf("got result %q, want %q", conn.Status(), telnettest.AuthSuccessWelcomeMessage)
+		}
+	})
+}
+
+// countingDialer counts opened connections.
+type countingDialer struct {
+	net.Dialer
+	dials atomic.Int32
+}
+
+func (d *countingDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	d.dials.Add(1)
+
+	return d.Dialer.DialContext(ctx, network, address)
+}
+
+func TestSetDialer(t *testing.T) {
+	server := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+		telnettest.SetAuthHandler(authHandler),
+	)
+	defer server.Close()
+
+	dialer := new(countingDialer)
+
+	conn, err := telnet.Dial(server.Addr(), "password", telnet.SetDialer(dialer))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	if got := dialer.dials.Load(); got != 1 {
+		t.Errorf("got %d dials, want %d", got, 1)
+	}
+}
+
+func TestNewConn(t *testing.T) {
+	server := telnettest.NewUnstartedServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+		telnettest.SetAuthHandler(authHandler),
+		telnettest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	t.Run("auth success", func(t *testing.T) {
+		clientSide, serverSide := net.Pipe()
+
+		go server.ServeConn(serverSide)
+
+		conn, err := telnet.NewConn(clientSide, "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		result, err := conn.Execute("random")
+		if !errors.Is(err, telnet.ErrUnknownCommand) {
+			t.Fatalf("got err %q, want %q", err, telnet.ErrUnknownCommand)
+		}
+
+		if want := "*** ERROR: unknown command 'random'"; result != want {
+			t.Fatalf("got result %q, want %q", result, want)
+		}
+	})
+
+	t.Run("authentication failed", func(t *testing.T) {
+		clientSide, serverSide := net.Pipe()
+
+		go server.ServeConn(serverSide)
+
+		_, err := telnet.NewConn(clientSide, "wrong")
+		if !errors.Is(err, telnet.ErrAuthFailed) {
+			t.Errorf("got err %q, want %q", err, telnet.ErrAuthFailed)
+		}
+	})
+}
+
+func TestConn_ExecuteContext(t *testing.T) {
+	server := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password", CommandResponseDelay: 500 * time.Millisecond}),
+		telnettest.SetAuthHandler(authHandler),
+		telnettest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	conn, err := telnet.Dial(server.Addr(), "password", telnet.SetClearResponse(true))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := conn.ExecuteContext(ctx, "help")
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got err %q, want %q", err, context.Canceled)
+		}
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err := conn.ExecuteContext(ctx, "help")
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got err %q, want %q", err, context.DeadlineExceeded)
+		}
+	})
+}
+
+func TestConn_ExecuteContext_Drain(t *testing.T) {
+	server := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+		telnettest.SetAuthHandler(authHandler),
+		telnettest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	conn, err := telnet.Dial(server.Addr(), "password", telnet.SetClearResponse(true))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	if _, err := conn.ExecuteContext(ctx, "slowish"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got err %q, want %q", err, context.DeadlineExceeded)
+	}
+
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Errorf("got elapsed %s, want the canceled command to return immediately", elapsed)
+	}
+
+	// The rest of the canceled response must not be taken by the next command.
+	result, err := conn.Execute("help")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if result != "lorem ipsum dolor sit amet" {
+		t.Fatalf("got result %q, want %q", result, "lorem ipsum dolor sit amet")
+	}
+}
+
 func TestConn_Execute(t *testing.T) {
 	server := telnettest.NewServer(
 		telnettest.SetSettings(telnettest.Settings{Password: "password"}),
@@ -155,8 +421,17 @@ func TestConn_Execute(t *testing.T) {
 		defer conn.Close()
 
 		result, err := conn.Execute("random")
-		if err != nil {
-			t.Fatalf("got err %q, want %v", err, nil)
+		if !errors.Is(err, telnet.ErrUnknownCommand) {
+			t.Fatalf("got err %q, want %q", err, telnet.ErrUnknownCommand)
+		}
+
+		var commandErr *telnet.CommandError
+		if !errors.As(err, &commandErr) {
+			t.Fatalf("got err %T, want %T", err, commandErr)
+		}
+
+		if commandErr.Command != "random" || commandErr.Message != "unknown command 'random'" {
+			t.Fatalf("got err %+v, want command %q and message %q", commandErr, "random", "unknown command 'random'")
 		}
 
 		resultWant := "*** ERROR: unknown command 'random'"
@@ -165,6 +440,59 @@ func TestConn_Execute(t *testing.T) {
 		}
 	})
 
+	t.Run("invalid command", func(t *testing.T) {
+		conn, err := telnet.Dial(server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		for _, command := range []string{"say hi\r\nshutdown", "say hi\nshutdown", "say hi\x00"} {
+			if _, err := conn.Execute(command); !errors.Is(err, telnet.ErrCommandInvalid) {
+				t.Errorf("got err %q, want %q", err, telnet.ErrCommandInvalid)
+			}
+		}
+	})
+
+	t.Run("exception", func(t *testing.T) {
+		conn, err := telnet.Dial(server.Addr(), "password", telnet.SetClearResponse(true))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		result, err := conn.Execute("fail")
+		if !errors.Is(err, telnet.ErrCommandFailed) {
+			t.Fatalf("got err %q, want %q", err, telnet.ErrCommandFailed)
+		}
+
+		var commandErr *telnet.CommandError
+		if !errors.As(err, &commandErr) || commandErr.Message != "NullReferenceException: Object reference not set to an instance of an object" {
+			t.Fatalf("got err %q, want exception message", err)
+		}
+
+		if !strings.HasPrefix(result, "2020-11-14T23:09:20 31220.644 EXC NullReferenceException") {
+			t.Fatalf("got result %q, want the raw exception", result)
+		}
+	})
+
+	t.Run("background errors", func(t *testing.T) {
+		conn, err := telnet.Dial(server.Addr(), "password", telnet.SetClearResponse(true))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		result, err := conn.Execute("noisy")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if !strings.HasPrefix(result, "ok") {
+			t.Fatalf("got result %q, want %q first", result, "ok")
+		}
+	})
+
 	t.Run("success help command", func(t *testing.T) {
 		conn, err := telnet.Dial(server.Addr(), "password", telnet.SetClearResponse(true))
 		if err != nil {
@@ -203,8 +531,8 @@ func TestConn_Execute(t *testing.T) {
 
 		// Command 2
 		result, err = conn.Execute("random")
-		if err != nil {
-			t.Fatalf("got err %q, want %v", err, nil)
+		if !errors.Is(err, telnet.ErrUnknownCommand) {
+			t.Fatalf("got err %q, want %q", err, telnet.ErrUnknownCommand)
 		}
 
 		resultWant = "*** ERROR: unknown command 'random'"
@@ -383,8 +711,8 @@ of your current perk levels in a CSV file next to it.
 			// Command 2
 			needle = "*** ERROR: unknown command 'status'"
 			result, err = conn.Execute("status")
-			if err != nil {
-				t.Fatalf("got err %q, want %v", err, nil)
+			if !errors.Is(err, telnet.ErrUnknownCommand) {
+				t.Fatalf("got err %q, want %q", err, telnet.ErrUnknownCommand)
 			}
 
 			if result != needle {
@@ -405,69 +733,779 @@ of your current perk levels in a CSV file next to it.
 	}
 }
 
-func TestConn_Interactive(t *testing.T) {
-	server := telnettest.NewUnstartedServer()
-	server.Settings.Password = "password"
-	server.SetAuthHandler(authHandler)
-	server.SetCommandHandler(commandHandler)
-	server.Start()
+func TestConn_Execute_Framing(t *testing.T) {
+	server := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+		telnettest.SetAuthHandler(authHandler),
+		telnettest.SetCommandHandler(commandHandler),
+	)
 	defer server.Close()
 
-	t.Run("connection refused", func(t *testing.T) {
-		wantErrContains := "connect: connection refused"
+	tests := []struct {
+		name    string
+		framing telnet.Framing
+		command string
+		want    string
+		quiet   time.Duration
+		minTime time.Duration
+		maxTime time.Duration
+	}{
+		{name: "quiet", framing: telnet.FramingQuiet, command: "help", want: "lorem ipsum dolor sit amet", maxTime: telnet.ExecuteTickTimeout},
+		{name: "quiet after echo", framing: telnet.FramingQuiet, command: "late", want: "done", maxTime: telnet.ExecuteTickTimeout},
+		{name: "sentinel", framing: telnet.FramingSentinel, command: "slow", want: "done", maxTime: telnet.ExecuteTickTimeout},
+		{name: "sentinel without quiet period", framing: telnet.FramingSentinel, command: "help", want: "lorem ipsum dolor sit amet", quiet: time.Second, maxTime: 500 * time.Millisecond},
+		{name: "sentinel pause", framing: telnet.FramingSentinel, command: "slowish", want: "first\r\nsecond", maxTime: telnet.ExecuteTickTimeout},
+		{name: "sleep", framing: telnet.FramingSleep, command: "help", want: "lorem ipsum dolor sit amet", minTime: telnet.ExecuteTickTimeout},
+	}
 
-		r, w := bytes.Buffer{}, bytes.Buffer{}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			options := []telnet.Option{telnet.SetClearResponse(true), telnet.SetFraming(tt.framing)}
+			if tt.quiet != 0 {
+				options = append(options, telnet.SetQuietPeriod(tt.quiet))
+			}
 
-		err := telnet.DialInteractive(&r, &w, "127.0.0.2:12345", "password")
-		if err == nil || !strings.Contains(err.Error(), wantErrContains) {
-			t.Errorf("got err %q, want to contain %q", err, wantErrContains)
-		}
-	})
+			conn, err := telnet.Dial(server.Addr(), "password", options...)
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+			defer conn.Close()
 
-	t.Run("unknown command", func(t *testing.T) {
-		needle := telnet.ResponseEnterPassword + telnet.CRLF +
-			telnet.ResponseAuthSuccess + telnet.CRLF + telnet.CRLF + telnet.CRLF + telnet.CRLF +
-			telnettest.AuthSuccessWelcomeMessage + telnet.CRLF + telnet.CRLF +
-			"*** ERROR: unknown command 'random'" + telnet.CRLF
+			start := time.Now()
 
-		r, w := bytes.Buffer{}, bytes.Buffer{}
+			result, err := conn.Execute(tt.command)
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
 
-		r.WriteString("random" + "\n")
-		r.WriteString(telnet.ForcedExitCommand + "\n")
+			elapsed := time.Since(start)
 
-		err := telnet.DialInteractive(&r, &w, server.Addr(), "password")
-		if err != nil {
-			t.Fatalf("got err %q, want %v", err, nil)
-		}
+			if result != tt.want {
+				t.Fatalf("got result %q, want %q", result, tt.want)
+			}
 
-		if w.String() != needle {
-			t.Fatalf("got result %q, want %q", w.String(), needle)
-		}
-	})
+			if elapsed < tt.minTime || (tt.maxTime != 0 && elapsed > tt.maxTime) {
+				t.Errorf("got elapsed %s, want between %s and %s", elapsed, tt.minTime, tt.maxTime)
+			}
+		})
+	}
+}
 
-	t.Run("success help command", func(t *testing.T) {
-		// TODO: server.Addr() in needle must be client address.
-		// This is impossible to check in current TELNET implementation.
-		needle := telnet.ResponseEnterPassword + telnet.CRLF +
-			telnet.ResponseAuthSuccess + telnet.CRLF + telnet.CRLF + telnet.CRLF + telnet.CRLF +
-			telnettest.AuthSuccessWelcomeMessage + telnet.CRLF + telnet.CRLF +
-			fmt.Sprintf("2020-11-14T23:09:20 31220.643 "+telnet.ResponseINFLayout, "help", server.Addr()) + telnet.CRLF +
-			"lorem ipsum dolor sit amet" + telnet.CRLF
+func TestConn_Execute_Timeout(t *testing.T) {
+	server := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+		telnettest.SetAuthHandler(authHandler),
+		telnettest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
 
-		r, w := bytes.Buffer{}, bytes.Buffer{}
+	conn, err := telnet.Dial(server.Addr(), "password", telnet.SetExecuteTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
 
-		r.WriteString("help" + "\n")
-		r.WriteString(telnet.ForcedExitCommand + "\n")
+	if _, err := conn.Execute("slow"); !errors.Is(err, telnet.ErrResponseTimeout) {
+		t.Fatalf("got err %q, want %q", err, telnet.ErrResponseTimeout)
+	}
 
-		err := telnet.DialInteractive(&r, &w, server.Addr(), "password", telnet.SetExitCommand("exit"))
-		if err != nil {
-			t.Fatalf("got err %q, want %v", err, nil)
-		}
+	// The connection is out of sync with the command queue.
+	select {
+	case <-conn.Done():
+	case <-time.After(time.Second):
+		t.Fatal("got open connection, want closed after the response timeout")
+	}
+}
 
-		if !strings.Contains(w.String(), "help") {
-			t.Fatalf("got result %q, want to contain %q", w.String(), needle)
-		}
-	})
+func TestConn_Execute_ConnectionLost(t *testing.T) {
+	server := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+		telnettest.SetAuthHandler(authHandler),
+		telnettest.SetCommandHandler(func(c *telnettest.Context) {
+			if c.Request() != telnet.CommandListPlayers {
+				commandHandler(c)
+
+				return
+			}
+
+			// The server is restarted in the middle of the response.
+			c.Writer().WriteString(fmt.Sprintf("2020-11-14T23:09:20 31220.643 "+telnet.ResponseINFLayout, c.Request(), c.Conn().RemoteAddr()) + telnet.CRLF)
+			c.Writer().WriteString("1. id=171, Player, pos=(-1.0, 61.1, 10.0)" + telnet.CRLF)
+			c.Writer().Flush()
+			c.Server().CloseClientConnections()
+		}),
+	)
+	defer server.Close()
+
+	conn, err := telnet.Dial(server.Addr(), "password", telnet.SetClearResponse(true))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	result, err := conn.Execute(telnet.CommandListPlayers)
+	if !errors.Is(err, telnet.ErrConnectionLost) {
+		t.Fatalf("got err %q, want %q", err, telnet.ErrConnectionLost)
+	}
+
+	if !strings.Contains(result, "id=171") {
+		t.Errorf("got result %q, want the partial response", result)
+	}
+}
+
+func TestConn_Execute_Concurrent(t *testing.T) {
+	server := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+		telnettest.SetAuthHandler(authHandler),
+		telnettest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	conn, err := telnet.Dial(server.Addr(), "password", telnet.SetClearResponse(true), telnet.SetQuietPeriod(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	const workers, commands = 8, 5
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, workers*commands)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func(w int) {
+			defer wg.Done()
+
+			for i := 0; i < commands; i++ {
+				want := fmt.Sprintf("worker %d command %d", w, i)
+
+				result, err := conn.Execute("echo " + want)
+				if err != nil {
+					errs <- err
+
+					continue
+				}
+
+				if result != want {
+					errs <- fmt.Errorf("got result %q, want %q", result, want)
+				}
+			}
+		}(w)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestConn_Execute_MaxBufferSize(t *testing.T) {
+	server := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+		telnettest.SetAuthHandler(authHandler),
+		telnettest.SetCommandHandler(bulkHandler),
+	)
+	defer server.Close()
+
+	const limit = 4096
+
+	tests := []struct {
+		name    string
+		policy  telnet.OverflowPolicy
+		err     error
+		framing telnet.Framing
+	}{
+		{name: "drop newest", policy: telnet.DropNewest},
+		{name: "drop oldest", policy: telnet.DropOldest},
+		{name: "fail", policy: telnet.FailOnOverflow, err: telnet.ErrResponseTooLarge},
+		{name: "fail sentinel", policy: telnet.FailOnOverflow, err: telnet.ErrResponseTooLarge, framing: telnet.FramingSentinel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := telnet.Dial(server.Addr(), "password",
+				telnet.SetMaxBufferSize(limit, tt.policy), telnet.SetFraming(tt.framing))
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+			defer conn.Close()
+
+			result, err := conn.Execute("bulk 10000")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got err %q, want %v", err, tt.err)
+			}
+
+			if len(result) == 0 || len(result) > limit {
+				t.Errorf("got result length %d, want up to %d", len(result), limit)
+			}
+
+			if tt.policy == telnet.DropOldest && strings.Contains(result, "bulk") {
+				t.Errorf("got result %q, want the oldest data to be dropped", result[:100])
+			}
+
+			if got := conn.Stats().DroppedBytes; got == 0 {
+				t.Errorf("got dropped %d, want more than %d", got, 0)
+			}
+
+			result, err = conn.Execute("help")
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			if !strings.HasSuffix(result, "lorem ipsum dolor sit amet") {
+				t.Errorf("got result %q, want %q", result, "lorem ipsum dolor sit amet")
+			}
+		})
+	}
+}
+
+func TestConn_Done(t *testing.T) {
+	server := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+		telnettest.SetAuthHandler(authHandler),
+		telnettest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	t.Run("closed", func(t *testing.T) {
+		conn, err := telnet.Dial(server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		select {
+		case <-conn.Done():
+			t.Fatal("got done connection, want alive")
+		default:
+		}
+
+		if err := conn.Err(); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		var wg sync.WaitGroup
+
+		errs := make([]error, 5)
+		for i := range errs {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				errs[i] = conn.Close()
+			}(i)
+		}
+
+		wg.Wait()
+
+		for _, err := range errs {
+			if err != errs[0] {
+				t.Errorf("got err %q, want %q", err, errs[0])
+			}
+		}
+
+		select {
+		case <-conn.Done():
+		default:
+			t.Fatal("got alive connection, want done after Close")
+		}
+
+		if err := conn.Err(); !errors.Is(err, net.ErrClosed) {
+			t.Errorf("got err %q, want %q", err, net.ErrClosed)
+		}
+	})
+
+	t.Run("lost", func(t *testing.T) {
+		conn, err := telnet.Dial(server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		server.CloseClientConnections()
+
+		select {
+		case <-conn.Done():
+		case <-time.After(time.Second):
+			t.Fatal("got alive connection, want done after server closed it")
+		}
+
+		if err := conn.Err(); !errors.Is(err, telnet.ErrConnectionLost) {
+			t.Errorf("got err %q, want %q", err, telnet.ErrConnectionLost)
+		}
+
+		// The commands are not written to the dead connection.
+		for i := 0; i < 3; i++ {
+			if _, err := conn.Execute("help"); !errors.Is(err, telnet.ErrConnectionLost) {
+				t.Errorf("got err %q, want %q", err, telnet.ErrConnectionLost)
+			}
+		}
+
+		if errs := conn.Stats().Errors; errs[telnet.ErrorTypeConnectionLost] != 3 {
+			t.Errorf("got errors %v, want %d %s", errs, 3, telnet.ErrorTypeConnectionLost)
+		}
+	})
+}
+
+func TestConn_Subscribe(t *testing.T) {
+	server := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+		telnettest.SetAuthHandler(authHandler),
+		telnettest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	chat := func(i int) string {
+		return fmt.Sprintf("2020-11-14T23:09:21 31221.643 INF Chat (from 'Steam_1', entity id '171', to 'Global'): 'Player': hello %d", i)
+	}
+
+	t.Run("receive lines", func(t *testing.T) {
+		conn, err := telnet.Dial(server.Addr(), "password", telnet.SetClearResponse(true),
+			telnet.SetFraming(telnet.FramingQuiet), telnet.SetQuietPeriod(20*time.Millisecond))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		sub := conn.Subscribe()
+		defer sub.Close()
+
+		result, err := conn.Execute("chat")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "ok" {
+			t.Fatalf("got result %q, want %q", result, "ok")
+		}
+
+		for i := 1; i <= 3; i++ {
+			select {
+			case line := <-sub.Lines():
+				if line.Raw != chat(i) {
+					t.Errorf("got line %q, want %q", line.Raw, chat(i))
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("line %d is not received", i)
+			}
+		}
+	})
+
+	t.Run("log line during response", func(t *testing.T) {
+		conn, err := telnet.Dial(server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		sub := conn.Subscribe()
+		defer sub.Close()
+
+		if _, err := conn.Execute("interleaved"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		// Neither the command echo nor the output lines are delivered.
+		select {
+		case line := <-sub.Lines():
+			if line.Message != "Player connected, entityid=171" {
+				t.Errorf("got line %q, want player connected line", line.Raw)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("got no line, want the log line received during the response")
+		}
+
+		select {
+		case line := <-sub.Lines():
+			t.Errorf("got line %q, want no more lines", line.Raw)
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+
+	t.Run("drop oldest", func(t *testing.T) {
+		conn, err := telnet.Dial(server.Addr(), "password", telnet.SetFraming(telnet.FramingQuiet),
+			telnet.SetQuietPeriod(20*time.Millisecond), telnet.SetLogBuffer(1, telnet.DropOldest))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		sub := conn.Subscribe()
+		defer sub.Close()
+
+		if _, err := conn.Execute("chat"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		for deadline := time.Now().Add(time.Second); sub.Dropped() < 2 && time.Now().Before(deadline); {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		if sub.Dropped() != 2 {
+			t.Fatalf("got dropped %d, want %d", sub.Dropped(), 2)
+		}
+
+		if line := <-sub.Lines(); line.Raw != chat(3) {
+			t.Errorf("got line %q, want %q", line.Raw, chat(3))
+		}
+	})
+
+	t.Run("close connection", func(t *testing.T) {
+		conn, err := telnet.Dial(server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		sub := conn.Subscribe()
+		conn.Close()
+
+		if _, ok := <-sub.Lines(); ok {
+			t.Error("got open channel, want closed")
+		}
+	})
+}
+
+// syncBuffer is a goroutine-safe bytes.Buffer.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func TestSetLogger(t *testing.T) {
+	const password = "s3cr3t-pa55"
+
+	server := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: password}),
+		telnettest.SetAuthHandler(authHandler),
+		telnettest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	t.Run("records", func(t *testing.T) {
+		var out syncBuffer
+
+		logger := slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+		conn, err := telnet.Dial(server.Addr(), password, telnet.SetLogger(logger))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if _, err := conn.Execute("echo " + password); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if err := conn.Close(); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		records := out.String()
+		for _, msg := range []string{
+			"telnet: connected", "telnet: authenticated", "telnet: command sent",
+			"telnet: command executed", "telnet: connection closed",
+		} {
+			if !strings.Contains(records, msg) {
+				t.Errorf("got records %q, want to contain %q", records, msg)
+			}
+		}
+
+		if strings.Contains(records, password) {
+			t.Errorf("got records %q, want password to be redacted", records)
+		}
+
+		if !strings.Contains(records, telnet.Redacted) {
+			t.Errorf("got records %q, want to contain %q", records, telnet.Redacted)
+		}
+	})
+
+	t.Run("auth failed", func(t *testing.T) {
+		var out syncBuffer
+
+		logger := slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+		_, err := telnet.Dial(server.Addr(), "wrong", telnet.SetLogger(logger))
+		if !errors.Is(err, telnet.ErrAuthFailed) {
+			t.Fatalf("got err %q, want %q", err, telnet.ErrAuthFailed)
+		}
+
+		records := out.String()
+		if !strings.Contains(records, "level=WARN msg=\"telnet: authentication failed\"") {
+			t.Errorf("got records %q, want to contain auth failed warning", records)
+		}
+
+		if strings.Contains(records, "wrong") {
+			t.Errorf("got records %q, want password to be redacted", records)
+		}
+	})
+}
+
+// terminalType answers TERMINAL-TYPE option negotiation (RFC 1091).
+type terminalType string
+
+func (tt terminalType) Negotiate(command byte) byte {
+	if command == telnet.DO {
+		return telnet.WILL
+	}
+
+	return 0
+}
+
+func (tt terminalType) Subnegotiate(data []byte) []byte {
+	if len(data) == 1 && data[0] == 1 {
+		return append([]byte{0}, tt...)
+	}
+
+	return nil
+}
+
+// negotiationServer starts raw TCP server which sends TELNET command sequences
+// and expects the client to answer them with want bytes.
+func negotiationServer(t *testing.T, send []byte, want []byte) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		conn.Write(append(send, telnet.ResponseEnterPassword+telnet.CRLF...))
+
+		reply := make([]byte, len(want))
+		if _, err := io.ReadFull(conn, reply); err != nil || !bytes.Equal(reply, want) {
+			t.Errorf("got reply %v, want %v", reply, want)
+
+			return
+		}
+
+		reader := bufio.NewReader(conn)
+		if _, err := reader.ReadString('\n'); err != nil {
+			return
+		}
+
+		conn.Write([]byte(telnet.ResponseAuthSuccess + telnet.CRLF + telnet.CRLF))
+		conn.Write([]byte{telnet.IAC, telnet.SB, 1, 2, telnet.IAC, telnet.IAC, telnet.IAC, telnet.SE})
+		conn.Write([]byte("Server IP:   127.0.0.1" + telnet.CRLF + telnet.CRLF + telnet.ResponseWelcome + telnet.CRLF))
+
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			conn.Write([]byte{'l', 'o', telnet.IAC, telnet.NOP, 'r', 'e', 'm', telnet.IAC, telnet.IAC})
+			conn.Write([]byte(strings.TrimSpace(line) + telnet.CRLF))
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func TestConn_Negotiation(t *testing.T) {
+	t.Run("refuse by default", func(t *testing.T) {
+		addr := negotiationServer(t,
+			[]byte{telnet.IAC, telnet.DO, 1, telnet.IAC, telnet.WILL, 3, telnet.IAC, telnet.DONT, 5},
+			[]byte{telnet.IAC, telnet.WONT, 1, telnet.IAC, telnet.DONT, 3},
+		)
+
+		conn, err := telnet.Dial(addr, "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if conn.Status() != "Server IP:   127.0.0.1" {
+			t.Fatalf("got status %q, want %q", conn.Status(), "Server IP:   127.0.0.1")
+		}
+
+		result, err := conn.Execute("help")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if want := "lorem\xffhelp"; result != want {
+			t.Fatalf("got result %q, want %q", result, want)
+		}
+	})
+
+	t.Run("broken subnegotiation", func(t *testing.T) {
+		// The subnegotiation is interrupted by DO command, which must be
+		// answered.
+		addr := negotiationServer(t,
+			[]byte{telnet.IAC, telnet.SB, 24, 1, telnet.IAC, telnet.DO, 1},
+			[]byte{telnet.IAC, telnet.WONT, 1},
+		)
+
+		conn, err := telnet.Dial(addr, "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+	})
+
+	t.Run("option handler", func(t *testing.T) {
+		const terminalTypeOption = 24
+
+		addr := negotiationServer(t,
+			[]byte{telnet.IAC, telnet.DO, terminalTypeOption, telnet.IAC, telnet.SB, terminalTypeOption, 1, telnet.IAC, telnet.SE},
+			append(
+				[]byte{telnet.IAC, telnet.WILL, terminalTypeOption, telnet.IAC, telnet.SB, terminalTypeOption, 0},
+				append([]byte("xterm"), telnet.IAC, telnet.SE)...,
+			),
+		)
+
+		conn, err := telnet.Dial(addr, "password", telnet.SetOptionHandler(terminalTypeOption, terminalType("xterm")))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+	})
+}
+
+func TestConn_Interactive(t *testing.T) {
+	server := telnettest.NewUnstartedServer()
+	server.Settings.Password = "password"
+	server.SetAuthHandler(authHandler)
+	server.SetCommandHandler(commandHandler)
+	server.Start()
+	defer server.Close()
+
+	t.Run("connection refused", func(t *testing.T) {
+		wantErrContains := "connect: connection refused"
+
+		r, w := bytes.Buffer{}, bytes.Buffer{}
+
+		err := telnet.DialInteractive(&r, &w, "127.0.0.2:12345", "password")
+		if err == nil || !strings.Contains(err.Error(), wantErrContains) {
+			t.Errorf("got err %q, want to contain %q", err, wantErrContains)
+		}
+	})
+
+	t.Run("unknown command", func(t *testing.T) {
+		needle := telnet.ResponseEnterPassword + telnet.CRLF +
+			telnet.ResponseAuthSuccess + telnet.CRLF + telnet.CRLF + telnet.CRLF + telnet.CRLF +
+			telnettest.AuthSuccessWelcomeMessage + telnet.CRLF + telnet.CRLF +
+			"*** ERROR: unknown command 'random'" + telnet.CRLF
+
+		r, w := bytes.Buffer{}, bytes.Buffer{}
+
+		r.WriteString("random" + "\n")
+		r.WriteString(telnet.ForcedExitCommand + "\n")
+
+		err := telnet.DialInteractive(&r, &w, server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if w.String() != needle {
+			t.Fatalf("got result %q, want %q", w.String(), needle)
+		}
+	})
+
+	t.Run("success help command", func(t *testing.T) {
+		// TODO: server.Addr() in needle must be client address.
+		// This is impossible to check in current TELNET implementation.
+		needle := telnet.ResponseEnterPassword + telnet.CRLF +
+			telnet.ResponseAuthSuccess + telnet.CRLF + telnet.CRLF + telnet.CRLF + telnet.CRLF +
+			telnettest.AuthSuccessWelcomeMessage + telnet.CRLF + telnet.CRLF +
+			fmt.Sprintf("2020-11-14T23:09:20 31220.643 "+telnet.ResponseINFLayout, "help", server.Addr()) + telnet.CRLF +
+			"lorem ipsum dolor sit amet" + telnet.CRLF
+
+		r, w := bytes.Buffer{}, bytes.Buffer{}
+
+		r.WriteString("help" + "\n")
+		r.WriteString(telnet.ForcedExitCommand + "\n")
+
+		err := telnet.DialInteractive(&r, &w, server.Addr(), "password", telnet.SetExitCommand("exit"))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if !strings.Contains(w.String(), "help") {
+			t.Fatalf("got result %q, want to contain %q", w.String(), needle)
+		}
+	})
+}
+
+// bulkHandler responds to "bulk <size>" with at least size bytes of log lines.
+func bulkHandler(c *telnettest.Context) {
+	var size int
+
+	if _, err := fmt.Sscanf(c.Request(), "bulk %d", &size); err != nil {
+		commandHandler(c)
+
+		return
+	}
+
+	c.Writer().WriteString(fmt.Sprintf("2020-11-14T23:09:20 31220.643 "+telnet.ResponseINFLayout, c.Request(), c.Conn().RemoteAddr()) + telnet.CRLF)
+
+	line := strings.Repeat("x", 100) + telnet.CRLF
+	for written := 0; written < size; written += len(line) {
+		c.Writer().WriteString(line)
+	}
+
+	c.Writer().Flush()
+}
+
+func BenchmarkConn_Execute(b *testing.B) {
+	server := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+		telnettest.SetAuthHandler(authHandler),
+		telnettest.SetCommandHandler(bulkHandler),
+	)
+	defer server.Close()
+
+	conn, err := telnet.Dial(server.Addr(), "password",
+		telnet.SetFraming(telnet.FramingSentinel), telnet.SetQuietPeriod(5*time.Millisecond))
+	if err != nil {
+		b.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	for _, size := range []int{1 << 20, 4 << 20, 16 << 20} {
+		b.Run(fmt.Sprintf("%dMB", size>>20), func(b *testing.B) {
+			b.SetBytes(int64(size))
+
+			for i := 0; i < b.N; i++ {
+				result, err := conn.Execute(fmt.Sprintf("bulk %d", size))
+				if err != nil {
+					b.Fatalf("got err %q, want %v", err, nil)
+				}
+
+				if len(result) < size {
+					b.Fatalf("got result length %d, want at least %d", len(result), size)
+				}
+			}
+		})
+	}
 }
 
 // getVar returns environment variable or default value.
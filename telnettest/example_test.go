@@ -1,6 +1,7 @@
 package telnettest_test
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -52,7 +53,7 @@ func ExampleServer() {
 	fmt.Println(response)
 
 	response, err = client.Execute("Hi!")
-	if err != nil {
+	if !errors.Is(err, telnet.ErrUnknownCommand) {
 		log.Fatal(err)
 	}
 
@@ -1,5 +1,7 @@
 package telnettest
 
+import "crypto/tls"
+
 // Option allows to inject Settings to Server.
 type Option func(s *Server)
 
@@ -23,3 +25,11 @@ func SetCommandHandler(handler HandlerFunc) Option {
 		s.SetCommandHandler(handler)
 	}
 }
+
+// SetTLS injects TLS configuration for TELNET Server. Pass an empty
+// configuration to use a self-signed certificate for the local host.
+func SetTLS(config *tls.Config) Option {
+	return func(s *Server) {
+		s.TLS = config
+	}
+}
@@ -3,6 +3,7 @@ package telnettest
 
 import (
 	"bufio"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
@@ -31,8 +32,14 @@ Press 'help' to get a list of all commands. Press 'exit' to end session.`
 // Server is an TELNET server listening on a system-chosen port on the
 // local loopback interface, for use in end-to-end TELNET tests.
 type Server struct {
-	Settings       Settings
-	Listener       net.Listener
+	Settings Settings
+	Listener net.Listener
+
+	// TLS is the optional TLS configuration. If it is set, the Server
+	// accepts TLS connections. A self-signed certificate is generated
+	// if the configuration has no certificates.
+	TLS *tls.Config
+
 	addr           string
 	authHandler    HandlerFunc
 	commandHandler HandlerFunc
@@ -125,6 +132,14 @@ func (s *Server) Start() {
 		panic("server already started")
 	}
 
+	if s.TLS != nil {
+		if len(s.TLS.Certificates) == 0 {
+			s.TLS.Certificates = []tls.Certificate{newLocalCertificate()}
+		}
+
+		s.Listener = tls.NewListener(s.Listener, s.TLS)
+	}
+
 	s.addr = s.Listener.Addr().String()
 	s.goServe()
 }
@@ -139,15 +154,33 @@ func (s *Server) Close() {
 	close(s.quit)
 	s.Listener.Close()
 
+	// Force-close any connections.
+	s.CloseClientConnections()
+
 	// Waiting for server connections.
 	s.wg.Wait()
+}
+
+// ServeConn serves TELNET requests on the connection opened by the caller,
+// for example with net.Pipe. It blocks until the connection is closed.
+func (s *Server) ServeConn(conn net.Conn) {
+	s.wg.Add(1)
+	s.handle(conn)
+}
 
+// CloseClientConnections closes any open TELNET connections to the Server.
+// The Server keeps accepting new connections.
+func (s *Server) CloseClientConnections() {
 	s.mu.Lock()
+	conns := make([]net.Conn, 0, len(s.connections))
 	for c := range s.connections {
-		// Force-close any connections.
-		s.closeConn(c)
+		conns = append(conns, c)
 	}
 	s.mu.Unlock()
+
+	for _, c := range conns {
+		s.closeConn(c)
+	}
 }
 
 // Addr returns IPv4 string Server address.
@@ -211,7 +244,7 @@ func (s *Server) handle(conn net.Conn) {
 		scanned := scanner.Scan()
 		if !scanned {
 			if err := scanner.Err(); err != nil {
-				if !errors.Is(err, io.EOF) {
+				if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
 					panic(fmt.Errorf("handle read request error: %w", err))
 				}
 
@@ -245,6 +278,11 @@ func (s *Server) closeConn(conn net.Conn) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if _, ok := s.connections[conn]; !ok {
+		// Already closed.
+		return
+	}
+
 	if err := conn.Close(); err != nil {
 		panic(fmt.Errorf("close conn error: %w", err))
 	}
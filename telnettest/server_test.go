@@ -1,6 +1,7 @@
 package telnettest_test
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"testing"
@@ -74,6 +75,35 @@ func TestNewServer(t *testing.T) {
 		}
 	})
 
+	t.Run("with TLS", func(t *testing.T) {
+		server := telnettest.NewServer(
+			telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+			telnettest.SetCommandHandler(handlers),
+			telnettest.SetTLS(&tls.Config{MinVersion: tls.VersionTLS12}),
+		)
+		defer server.Close()
+
+		if server.Certificate() == nil {
+			t.Fatal("got nil certificate, want self-signed certificate")
+		}
+
+		client, err := telnet.Dial(server.Addr(), "password",
+			telnet.SetTLSConfig(server.ClientTLSConfig()), telnet.SetClearResponse(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+
+		response, err := client.Execute("What do you do?")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if response != "I do it all." {
+			t.Errorf("got %q, want \"I do it all.\"", response)
+		}
+	})
+
 	t.Run("authentication failed", func(t *testing.T) {
 		server := telnettest.NewServer(telnettest.SetSettings(telnettest.Settings{Password: "password"}))
 		defer server.Close()
@@ -91,15 +121,16 @@ func TestNewServer(t *testing.T) {
 		server := telnettest.NewServer()
 		defer server.Close()
 
-		client, err := telnet.Dial(server.Addr(), "")
+		// EmptyHandler sends nothing, so do not wait for the response for long.
+		client, err := telnet.Dial(server.Addr(), "", telnet.SetExecuteTimeout(time.Second))
 		if err != nil {
 			t.Fatal(err)
 		}
 		defer client.Close()
 
 		response, err := client.Execute("whatever")
-		if err != nil {
-			t.Fatal(err)
+		if !errors.Is(err, telnet.ErrResponseTimeout) {
+			t.Fatalf("got err %q, want %q", err, telnet.ErrResponseTimeout)
 		}
 
 		if response != "" {
@@ -0,0 +1,76 @@
+package telnet
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// secure wraps conn with TLS client if TLS config is set. The server name
+// is taken from address unless it is set in the config.
+func secure(ctx context.Context, conn net.Conn, address string, settings Settings) (net.Conn, error) {
+	if settings.tlsConfig == nil {
+		return conn, nil
+	}
+
+	config := settings.tlsConfig
+	if config.ServerName == "" {
+		config = config.Clone()
+
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			host = address
+		}
+
+		config.ServerName = host
+	}
+
+	if settings.dialTimeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, settings.dialTimeout)
+		defer cancel()
+	}
+
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		_ = conn.Close()
+
+		return nil, fmt.Errorf("telnet: %w", err)
+	}
+
+	return tlsConn, nil
+}
+
+// isSecure reports whether the password may be sent over conn dialed to
+// address: conn is encrypted with TLS, the transport is trusted or address
+// is on the local host. The remote address of conn is not checked, since
+// it is the proxy address when Dialer is a proxy. Addresses of other
+// networks than TCP and UDP, such as Unix sockets and pipes, are not
+// trusted unless SetTrustedTransport is set.
+func isSecure(conn net.Conn, network string, address string, settings Settings) bool {
+	if _, ok := conn.(*tls.Conn); ok || settings.trustedTransport {
+		return true
+	}
+
+	switch network {
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6":
+	default:
+		return false
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
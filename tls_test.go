@@ -0,0 +1,136 @@
+package telnet_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/gorcon/telnet"
+	"github.com/gorcon/telnet/telnettest"
+)
+
+// remoteAddr is the address of the remote server, which is reached
+// through proxyDialer.
+const remoteAddr = "203.0.113.1:8081"
+
+// proxyDialer dials target instead of the requested address, like a local
+// proxy does.
+type proxyDialer struct {
+	net.Dialer
+	target string
+}
+
+func (d *proxyDialer) DialContext(ctx context.Context, network, _ string) (net.Conn, error) {
+	return d.Dialer.DialContext(ctx, network, d.target)
+}
+
+func TestSetTLSConfig(t *testing.T) {
+	server := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+		telnettest.SetAuthHandler(authHandler),
+		telnettest.SetCommandHandler(commandHandler),
+		telnettest.SetTLS(&tls.Config{MinVersion: tls.VersionTLS12}),
+	)
+	defer server.Close()
+
+	t.Run("trusted certificate", func(t *testing.T) {
+		conn, err := telnet.Dial(server.Addr(), "password",
+			telnet.SetTLSConfig(server.ClientTLSConfig()), telnet.SetClearResponse(true))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		result, err := conn.Execute("help")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "lorem ipsum dolor sit amet" {
+			t.Fatalf("got result %q, want %q", result, "lorem ipsum dolor sit amet")
+		}
+	})
+
+	t.Run("unknown certificate", func(t *testing.T) {
+		_, err := telnet.Dial(server.Addr(), "password", telnet.SetTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}))
+
+		var certErr *tls.CertificateVerificationError
+		if !errors.As(err, &certErr) {
+			t.Errorf("got err %q, want certificate verification error", err)
+		}
+	})
+
+	t.Run("refuse plaintext with TLS", func(t *testing.T) {
+		config := server.ClientTLSConfig()
+		config.ServerName = "127.0.0.1"
+
+		conn, err := telnet.Dial(remoteAddr, "password",
+			telnet.SetDialer(&proxyDialer{target: server.Addr()}),
+			telnet.SetTLSConfig(config),
+			telnet.SetRefusePlaintextAuth(true),
+		)
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+	})
+}
+
+func TestSetRefusePlaintextAuth(t *testing.T) {
+	server := telnettest.NewServer(
+		telnettest.SetSettings(telnettest.Settings{Password: "password"}),
+		telnettest.SetAuthHandler(authHandler),
+	)
+	defer server.Close()
+
+	t.Run("remote server behind local proxy", func(t *testing.T) {
+		_, err := telnet.Dial(remoteAddr, "password",
+			telnet.SetDialer(&proxyDialer{target: server.Addr()}), telnet.SetRefusePlaintextAuth(true))
+		if !errors.Is(err, telnet.ErrInsecureAuth) {
+			t.Errorf("got err %q, want %q", err, telnet.ErrInsecureAuth)
+		}
+	})
+
+	t.Run("trusted transport", func(t *testing.T) {
+		conn, err := telnet.Dial(remoteAddr, "password", telnet.SetDialer(&proxyDialer{target: server.Addr()}),
+			telnet.SetRefusePlaintextAuth(true), telnet.SetTrustedTransport(true))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+	})
+
+	t.Run("pipe", func(t *testing.T) {
+		client, server := net.Pipe()
+		defer server.Close()
+
+		go io.Copy(io.Discard, server)
+
+		_, err := telnet.NewConn(client, "password", telnet.SetRefusePlaintextAuth(true))
+		if !errors.Is(err, telnet.ErrInsecureAuth) {
+			t.Errorf("got err %q, want %q", err, telnet.ErrInsecureAuth)
+		}
+	})
+
+	t.Run("local server", func(t *testing.T) {
+		conn, err := telnet.Dial(server.Addr(), "password", telnet.SetRefusePlaintextAuth(true))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+	})
+
+	t.Run("interactive", func(t *testing.T) {
+		var r, w bytes.Buffer
+
+		err := telnet.DialInteractive(&r, &w, remoteAddr, "password",
+			telnet.SetDialer(&proxyDialer{target: server.Addr()}), telnet.SetRefusePlaintextAuth(true))
+		if !errors.Is(err, telnet.ErrInsecureAuth) {
+			t.Errorf("got err %q, want %q", err, telnet.ErrInsecureAuth)
+		}
+	})
+}